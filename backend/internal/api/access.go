@@ -0,0 +1,107 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"oracle-backend/internal/models"
+	"oracle-backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetProjectAccess 获取项目在指定链上的文件下载权限
+func GetProjectAccess(c *gin.Context) {
+	access, err := service.GetProjectAccess(c.Query("chainId"), c.Param("pid"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to get project access",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    access,
+	})
+}
+
+// UpdateProjectAccess 由已登录的授权提交者修改项目的文件下载权限
+func UpdateProjectAccess(c *gin.Context) {
+	address, _ := SessionAddress(c)
+
+	var req models.UpdateProjectAccessRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid access request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	access, err := service.UpdateProjectAccess(c.Request.Context(), address, c.Query("chainId"), c.Param("pid"), &req)
+	if errors.Is(err, service.ErrAccessDenied) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "无权修改项目的下载权限",
+			"details": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to update project access",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    access,
+	})
+}
+
+// CreateDownloadURL 为已登录且有权下载的地址生成限时的签名下载链接
+func CreateDownloadURL(c *gin.Context) {
+	address, _ := SessionAddress(c)
+
+	var req models.DownloadURLRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid download url request",
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	downloadURL, err := service.CreateDownloadURL(c.Request.Context(), address, c.Query("chainId"), c.Param("pid"), c.Param("hash"), req.TTLSeconds)
+	if errors.Is(err, service.ErrFileNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "File not found",
+			"details": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, service.ErrAccessDenied) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "无权下载该文件",
+			"details": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to create download url",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    downloadURL,
+	})
+}
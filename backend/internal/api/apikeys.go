@@ -0,0 +1,91 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"oracle-backend/internal/models"
+	"oracle-backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader 使用API密钥上传时携带密钥的请求头
+const APIKeyHeader = "X-API-Key"
+
+// CreateAPIKey 为已登录的授权提交者创建限定链和项目的API密钥
+// 密钥原文只在响应中出现一次
+func CreateAPIKey(c *gin.Context) {
+	address, _ := SessionAddress(c)
+
+	var req models.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid api key request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	key, apiKey, err := service.CreateAPIKey(c.Request.Context(), address, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "创建API密钥失败",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"key":    key,
+			"apiKey": apiKey,
+		},
+	})
+}
+
+// ListAPIKeys 列出已登录地址创建的API密钥
+func ListAPIKeys(c *gin.Context) {
+	address, _ := SessionAddress(c)
+
+	keys, err := service.ListAPIKeys(address)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to list api keys",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    keys,
+	})
+}
+
+// RevokeAPIKey 吊销已登录地址创建的API密钥
+func RevokeAPIKey(c *gin.Context) {
+	address, _ := SessionAddress(c)
+
+	apiKey, err := service.RevokeAPIKey(address, c.Param("id"))
+	if errors.Is(err, service.ErrAPIKeyNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "API key not found",
+			"details": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to revoke api key",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    apiKey,
+	})
+}
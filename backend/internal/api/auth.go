@@ -0,0 +1,129 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"oracle-backend/internal/models"
+	"oracle-backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IssueAuthNonce 签发SIWE登录消息使用的nonce
+func IssueAuthNonce(c *gin.Context) {
+	nonce, err := service.IssueAuthNonce()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to issue nonce",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    nonce,
+	})
+}
+
+// VerifyAuth 验证SIWE登录消息和签名，返回会话令牌
+// 之后的请求通过Authorization: Bearer <token>携带令牌
+func VerifyAuth(c *gin.Context) {
+	var req models.SignInRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid sign-in request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	token, session, err := service.SignIn(c.Request.Context(), c.Request.Host, req.Message, req.Signature)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "登录失败",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data": gin.H{
+			"token":     token,
+			"address":   session.Address,
+			"chainId":   session.ChainID,
+			"expiresAt": session.ExpiresAt,
+		},
+	})
+}
+
+// Logout 注销当前会话
+func Logout(c *gin.Context) {
+	if err := service.SignOut(bearerToken(c)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to sign out",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+	})
+}
+
+// OptionalSession 携带会话令牌时验证并将已登录地址放入请求context，未携带时匿名访问
+// 携带了无效或过期的令牌时返回401，避免客户端误以为自己已登录
+func OptionalSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if bearerToken(c) == "" {
+			c.Next()
+			return
+		}
+		authenticate(c)
+	}
+}
+
+// RequireSession 要求请求携带有效的会话令牌，并将已登录地址放入请求context
+func RequireSession() gin.HandlerFunc {
+	return authenticate
+}
+
+// authenticate 验证会话令牌，成功时设置已登录地址，失败时中止请求
+func authenticate(c *gin.Context) {
+	session, err := service.LookupSession(bearerToken(c))
+	if errors.Is(err, service.ErrUnauthenticated) {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"error":   "未登录或登录已过期",
+			"details": "请通过 /api/auth/verify 登录",
+		})
+		return
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to verify session",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.Request = c.Request.WithContext(service.WithAuthAddress(c.Request.Context(), session.Address))
+	c.Next()
+}
+
+// SessionAddress 获取请求的已登录地址
+func SessionAddress(c *gin.Context) (string, bool) {
+	return service.AuthAddress(c.Request.Context())
+}
+
+// bearerToken 读取Authorization: Bearer请求头中的令牌
+func bearerToken(c *gin.Context) string {
+	scheme, token, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
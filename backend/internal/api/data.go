@@ -0,0 +1,141 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"oracle-backend/internal/config"
+	"oracle-backend/internal/models"
+	"oracle-backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetLatestProjectData 获取项目最新的数据记录，关联的文件按项目的可见性和已登录地址返回
+// pid: 项目ID（字符串或bytes32十六进制）
+func GetLatestProjectData(c *gin.Context) {
+	chain, pid, ok := resolveProject(c)
+	if !ok {
+		return
+	}
+	address, _ := SessionAddress(c)
+	record, err := service.GetLatestRecord(c.Request.Context(), chain, pid, address)
+	respondRecord(c, record, err)
+}
+
+// GetProjectData 获取项目指定数据ID的数据记录
+// did: 数据ID（bytes32十六进制或YYYY-MM-DD日期）
+func GetProjectData(c *gin.Context) {
+	chain, pid, ok := resolveProject(c)
+	if !ok {
+		return
+	}
+
+	client, err := service.ChainClient(chain)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "获取合约客户端失败",
+			"details": err.Error(),
+		})
+		return
+	}
+	did, err := service.ResolveDid(c.Request.Context(), client, c.Param("did"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid data id",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	address, _ := SessionAddress(c)
+	record, err := service.GetRecord(c.Request.Context(), chain, pid, did, address)
+	respondRecord(c, record, err)
+}
+
+// ListProjectData 按年月列出项目的数据记录
+// year: 年份；month: 月份（1-12）
+func ListProjectData(c *gin.Context) {
+	chain, pid, ok := resolveProject(c)
+	if !ok {
+		return
+	}
+
+	year, err := strconv.ParseUint(c.Query("year"), 10, 16)
+	if err != nil || year == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid year",
+			"details": "year must be between 1 and 65535",
+		})
+		return
+	}
+	month, err := strconv.ParseUint(c.Query("month"), 10, 8)
+	if err != nil || month < 1 || month > 12 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid month",
+			"details": "month must be between 1 and 12",
+		})
+		return
+	}
+
+	address, _ := SessionAddress(c)
+	records, err := service.ListRecordsByYearMonth(c.Request.Context(), chain, pid, uint16(year), uint8(month), address)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":   "查询链上数据失败",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    records,
+	})
+}
+
+// resolveProject 解析请求中的chainId查询参数和pid路径参数，失败时直接写入错误响应
+func resolveProject(c *gin.Context) (*config.ChainConfig, [32]byte, bool) {
+	chain, err := service.ResolveChain(c.Query("chainId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "不支持的链",
+			"details": err.Error(),
+		})
+		return nil, [32]byte{}, false
+	}
+
+	pid, err := service.ParseProjectID(c.Param("pid"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid project id",
+			"details": err.Error(),
+		})
+		return nil, [32]byte{}, false
+	}
+	return chain, pid, true
+}
+
+// respondRecord 写入单条数据记录的查询结果
+func respondRecord(c *gin.Context, record *models.DataRecordView, err error) {
+	if errors.Is(err, service.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Record not found",
+			"details": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":   "查询链上数据失败",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    record,
+	})
+}
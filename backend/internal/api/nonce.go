@@ -0,0 +1,33 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"oracle-backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IssueNonce 为地址签发上传签名使用的一次性nonce
+func IssueNonce(c *gin.Context) {
+	nonce, err := service.IssueNonce(c.Query("address"))
+	switch {
+	case errors.Is(err, service.ErrInvalidAddress):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid address",
+			"details": err.Error(),
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to issue nonce",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    nonce,
+	})
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"oracle-backend/internal/models"
+	"oracle-backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetUploadPolicy 获取项目在指定链上生效的上传策略
+func GetUploadPolicy(c *gin.Context) {
+	policy, err := service.GetUploadPolicy(c.Query("chainId"), c.Param("pid"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to get upload policy",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    policy,
+	})
+}
+
+// UpdateUploadPolicy 由已登录的授权提交者修改项目的上传策略
+func UpdateUploadPolicy(c *gin.Context) {
+	address, _ := SessionAddress(c)
+
+	var req models.UpdateUploadPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid upload policy request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	policy, err := service.UpdateUploadPolicy(c.Request.Context(), address, c.Query("chainId"), c.Param("pid"), &req)
+	if errors.Is(err, service.ErrAccessDenied) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "无权修改项目的上传策略",
+			"details": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to update upload policy",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    policy,
+	})
+}
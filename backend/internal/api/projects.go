@@ -0,0 +1,102 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"oracle-backend/internal/service"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+)
+
+// ListProjects 列出链上所有已注册的项目及其配置
+func ListProjects(c *gin.Context) {
+	chain, err := service.ResolveChain(c.Query("chainId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "不支持的链",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	projects, err := service.ListProjects(c.Request.Context(), chain)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":   "查询项目失败",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    projects,
+	})
+}
+
+// GetProject 获取项目在合约中的配置
+// pid: 项目ID（字符串或bytes32十六进制）
+func GetProject(c *gin.Context) {
+	chain, pid, ok := resolveProject(c)
+	if !ok {
+		return
+	}
+
+	project, err := service.GetProject(c.Request.Context(), chain, pid)
+	if errors.Is(err, service.ErrProjectNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Project not found",
+			"details": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":   "查询项目失败",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    project,
+	})
+}
+
+// ListAddressProjects 列出地址有权限提交数据的项目及其配置
+// addr: 提交者地址
+func ListAddressProjects(c *gin.Context) {
+	chain, err := service.ResolveChain(c.Query("chainId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "不支持的链",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if !common.IsHexAddress(c.Param("addr")) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid address",
+			"details": c.Param("addr"),
+		})
+		return
+	}
+
+	projects, err := service.ListProjectsByAddress(c.Request.Context(), chain, c.Param("addr"))
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":   "查询项目失败",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    projects,
+	})
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"oracle-backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VerifyProjectData 对账单条数据记录：从本地文件重新计算dataHash并与链上getDataHash比较，须由项目的所有者或授权提交者登录后调用
+// pid: 项目ID（字符串或bytes32十六进制）
+// did: 数据ID（bytes32十六进制或YYYY-MM-DD日期）
+func VerifyProjectData(c *gin.Context) {
+	chain, err := service.ResolveChain(c.Query("chainId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "不支持的链",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	pid, err := service.ParseProjectID(c.Param("pid"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid project id",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	client, err := service.ChainClient(chain)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "获取合约客户端失败",
+			"details": err.Error(),
+		})
+		return
+	}
+	did, err := service.ResolveDid(c.Request.Context(), client, c.Param("did"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid data id",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	address, _ := SessionAddress(c)
+	result, err := service.VerifyRecord(c.Request.Context(), address, chain, pid, did)
+	if errors.Is(err, service.ErrAccessDenied) {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "无权对账该项目的数据",
+			"details": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, service.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Record not found",
+			"details": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "对账失败",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// GetReconcileReport 返回最近一次后台对账任务的报告，只包含已登录地址是授权提交者的项目
+func GetReconcileReport(c *gin.Context) {
+	address, _ := SessionAddress(c)
+	report, err := service.ReconcileReportFor(c.Request.Context(), address)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":   "合约权限检查失败",
+			"details": err.Error(),
+		})
+		return
+	}
+	if report == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "对账任务尚未执行",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    report,
+	})
+}
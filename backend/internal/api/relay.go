@@ -0,0 +1,87 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"oracle-backend/internal/models"
+	"oracle-backend/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetRelayTransaction 查询中继模式下后端发送的交易状态
+func GetRelayTransaction(c *gin.Context) {
+	if !service.RelayEnabled() {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "中继模式未启用",
+		})
+		return
+	}
+
+	tx, err := service.GetRelayedTransaction(c.Param("hash"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Transaction not found",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    tx,
+	})
+}
+
+// RelayBatch 中继模式下由后端调用batchSubmitData，将已登录授权提交者的多条已上传提交一次上链
+func RelayBatch(c *gin.Context) {
+	if !service.RelayEnabled() {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "中继模式未启用",
+		})
+		return
+	}
+	address, _ := SessionAddress(c)
+
+	var req models.BatchRelayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid batch relay request",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	tx, err := service.RelayBatch(c.Request.Context(), address, &req)
+	switch {
+	case errors.Is(err, service.ErrAccessDenied):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "无权中继该项目的数据",
+			"details": err.Error(),
+		})
+		return
+	case errors.Is(err, service.ErrSubmissionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Submission not found",
+			"details": err.Error(),
+		})
+		return
+	case errors.Is(err, service.ErrRelayFailed):
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error":   "上链提交失败",
+			"details": err.Error(),
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "批量中继失败",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    tx,
+	})
+}
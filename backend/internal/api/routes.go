@@ -14,12 +14,45 @@ func SetupRoutes(router *gin.Engine) {
 		})
 	})
 
-	// 文件上传路由组
-	uploadGroup := router.Group("/api")
+	// SIWE（EIP-4361）登录
+	authGroup := router.Group("/api/auth")
 	{
+		authGroup.GET("/nonce", IssueAuthNonce)
+		authGroup.POST("/verify", VerifyAuth)
+		authGroup.POST("/logout", RequireSession(), Logout)
+	}
+
+	// 供无钱包程序上传使用的API密钥，须由授权提交者登录后管理
+	keyGroup := router.Group("/api/keys", RequireSession())
+	{
+		keyGroup.POST("", CreateAPIKey)
+		keyGroup.GET("", ListAPIKeys)
+		keyGroup.DELETE("/:id", RevokeAPIKey)
+	}
+
+	// 文件上传路由组，携带会话令牌时识别已登录地址
+	uploadGroup := router.Group("/api", OptionalSession())
+	{
+		uploadGroup.GET("/nonce", IssueNonce)
 		uploadGroup.POST("/upload", UploadFile)
+		uploadGroup.GET("/relay/tx/:hash", GetRelayTransaction)
+		uploadGroup.POST("/relay/batch", RequireSession(), RelayBatch)
+		uploadGroup.GET("/projects", ListProjects)
+		uploadGroup.GET("/projects/:pid", GetProject)
+		uploadGroup.GET("/addresses/:addr/projects", ListAddressProjects)
+		uploadGroup.GET("/projects/:pid/access", GetProjectAccess)
+		uploadGroup.PUT("/projects/:pid/access", RequireSession(), UpdateProjectAccess)
+		uploadGroup.POST("/projects/:pid/files/:hash/url", RequireSession(), CreateDownloadURL)
+		uploadGroup.GET("/projects/:pid/policy", GetUploadPolicy)
+		uploadGroup.PUT("/projects/:pid/policy", RequireSession(), UpdateUploadPolicy)
+		uploadGroup.GET("/projects/:pid/uploads", RequireSession(), ListProjectUploads)
+		uploadGroup.GET("/projects/:pid/latest", GetLatestProjectData)
+		uploadGroup.GET("/projects/:pid/data", ListProjectData)
+		uploadGroup.GET("/projects/:pid/data/:did", GetProjectData)
+		uploadGroup.GET("/projects/:pid/data/:did/verify", RequireSession(), VerifyProjectData)
+		uploadGroup.GET("/reconcile/report", RequireSession(), GetReconcileReport)
 	}
 
-	// 通过哈希值访问文件的路由
-	router.GET("/attach/:hash", GetFileByHash)
+	// 通过哈希值访问文件的路由，按项目的可见性检查已登录地址或签名下载链接
+	router.GET("/attach/*path", OptionalSession(), GetFileByHash)
 }
@@ -1,167 +1,395 @@
 package api
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
+	"net/url"
 	"oracle-backend/internal/models"
 	"oracle-backend/internal/service"
-	"os"
-	"path/filepath"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
 // UploadFile 处理文件上传请求
+// 请求体按顺序流式读取：表单字段须在文件之前，签名（或API密钥）验证通过后才读取文件，
+// 并在读取时按项目的上传策略检查文件数和大小，超过时立即停止，不会先将整个请求写入临时文件
 func UploadFile(c *gin.Context) {
+	// 服务端的文件总大小上限，项目的限制在读取文件时检查
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, service.MaxUploadRequestSize())
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to parse multipart form",
+			"details": err.Error(),
+		})
+		return
+	}
+	form, files, err := readUploadFields(reader)
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error":   "上传失败",
+			"details": fmt.Sprintf("请求超过 %d 字节的上限", maxBytesErr.Limit),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to parse multipart form",
+			"details": err.Error(),
+		})
+		return
+	}
+
 	// 获取表单数据
-	projectId := c.PostForm("projectId")
-	projectDescription := c.PostForm("projectDescription")
-	dataDate := c.PostForm("dataDate")
-	coreData := c.PostForm("coreData")
-	hashResults := c.PostForm("hashResults")
+	projectId := form.Get("projectId")
+	projectDescription := form.Get("projectDescription")
+	dataDate := form.Get("dataDate")
+	coreData := form.Get("coreData")
+	hashResults := form.Get("hashResults")
 
 	// 获取链ID
-	chainId := c.PostForm("chainId")
+	chainId := form.Get("chainId")
+
+	// 获取签名相关数据，signatureType为eip712（默认）或personal_sign
+	signatureType := form.Get("signatureType")
+	signatureData := form.Get("signatureData")
+	signature := form.Get("signature")
+	// 合约钱包（如Safe）无法从签名中恢复地址，需显式声明签名者地址
+	signerAddress := form.Get("signerAddress")
 
-	// 获取签名相关数据
-	signatureData := c.PostForm("signatureData")
-	signature := c.PostForm("signature")
+	// 无钱包的程序使用X-API-Key请求头认证，代替签名
+	apiKey := c.GetHeader(APIKeyHeader)
+
+	// 是否由后端代为提交上链（中继模式）
+	relay := form.Get("relay") == "true"
 
 	// 验证必要参数
-	if signatureData == "" || signature == "" {
+	if apiKey == "" && (signatureData == "" || signature == "") {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "签名数据不完整",
 			"details": "请提供完整的签名数据",
 		})
 		return
 	}
+	if relay && !service.RelayEnabled() {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "中继模式未启用",
+			"details": "服务端未配置提交者密钥，请使用钱包提交数据",
+		})
+		return
+	}
 
-	// 获取上传的文件
-	multipartForm, err := c.MultipartForm()
-	if err != nil {
+	// 调用服务层处理本次提交的所有文件：全部校验通过后一起保存，任一文件失败则都不保存
+	var (
+		results    []*models.FileUploadResult
+		submission *service.Submission
+	)
+	if apiKey != "" {
+		results, submission, err = service.UploadKeySubmission(c.Request.Context(), files, apiKey, projectId, dataDate, coreData, hashResults, chainId)
+	} else {
+		results, submission, err = service.UploadSubmission(c.Request.Context(), files, projectId, dataDate, coreData, hashResults, signatureType, signatureData, signature, signerAddress, chainId)
+	}
+	if errors.As(err, &maxBytesErr) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"error":   "上传失败",
+			"details": fmt.Sprintf("请求超过 %d 字节的上限", maxBytesErr.Limit),
+		})
+		return
+	}
+	if errors.Is(err, service.ErrMalformedUpload) {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Failed to parse multipart form",
 			"details": err.Error(),
 		})
 		return
 	}
-	files := multipartForm.File["files"]
-
-	var results []*models.FileUploadResult
-
-	// 处理每个上传的文件
-	for _, fileHeader := range files {
-		file, err := fileHeader.Open()
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   "Failed to open file",
-				"details": err.Error(),
-			})
-			return
+	if errors.Is(err, service.ErrSubmissionExists) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "上传失败",
+			"details": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, service.ErrInvalidAPIKey) {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "上传失败",
+			"details": err.Error(),
+		})
+		return
+	}
+	var uploadErr *service.UploadError
+	if errors.As(err, &uploadErr) {
+		status := http.StatusBadRequest
+		if uploadErr.Internal {
+			status = http.StatusInternalServerError
 		}
-		defer file.Close()
+		c.JSON(status, gin.H{
+			"error":   "上传失败",
+			"details": uploadErr.Error(),
+			"files":   uploadErr.Files,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "上传失败",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	data := gin.H{
+		"projectId":          projectId,
+		"projectDescription": projectDescription,
+		"dataDate":           dataDate,
+		"coreData":           coreData,
+		"coreDataValues":     submission.CoreDataValues,
+		"hashResults":        hashResults,
+		"signerAddress":      results[0].Signer, // 使用验证通过的签名者地址
+		"uploadedFiles":      results,
+	}
+	if submission.APIKeyID != "" {
+		data["apiKeyId"] = submission.APIKeyID
+	}
 
-		// 调用服务层处理文件上传，传递签名相关数据和链ID
-		result, err := service.UploadFile(file, fileHeader, projectId, projectDescription, hashResults, signatureData, signature, chainId)
+	// 中继模式：文件保存后由后端发送submitData交易
+	if relay {
+		relayTx, err := service.RelaySubmission(c.Request.Context(), submission)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error":   "上传失败",
+			c.JSON(http.StatusBadGateway, gin.H{
+				"error":   "上链提交失败",
 				"details": err.Error(),
+				"data":    data,
 			})
 			return
 		}
-
-		results = append(results, result)
+		data["relayTx"] = relayTx
+		data["txHash"] = relayTx.TxHash
 	}
 
 	// 返回成功响应
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"data": gin.H{
-			"projectId":          projectId,
-			"projectDescription": projectDescription,
-			"dataDate":           dataDate,
-			"coreData":           coreData,
-			"hashResults":        hashResults,
-			"signerAddress":      results[0].Signer, // 使用从签名中恢复的地址
-			"uploadedFiles":      results,
-		},
+		"data":    data,
 	})
 }
 
-// GetFileByHash 根据文件哈希值获取文件
+// GetFileByHash 根据完整的文件哈希值获取文件
+// 支持 /attach/<hash> 和限定链与项目的 /attach/<chainId>/<projectId>/<hash>，前者依次检查该内容所在的每个项目
+// 非公开项目的文件需要登录（授权提交者或允许列表中的地址），或使用?expires=&sig=的签名下载链接
 func GetFileByHash(c *gin.Context) {
-	// 获取哈希参数
-	hash := c.Param("hash")
-	if hash == "" {
+	parts := strings.Split(strings.Trim(c.Param("path"), "/"), "/")
+
+	var (
+		locs []*models.FileLocation
+		err  error
+	)
+	switch len(parts) {
+	case 1:
+		locs, err = service.LookupFiles(parts[0])
+	case 3:
+		var loc *models.FileLocation
+		loc, err = service.LookupProjectFile(parts[0], parts[1], parts[2])
+		locs = []*models.FileLocation{loc}
+	default:
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "File not found",
+		})
+		return
+	}
+
+	if errors.Is(err, service.ErrFileNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "File not found",
+			"details": err.Error(),
+		})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "File hash is required",
+			"error":   "Invalid file request",
+			"details": err.Error(),
 		})
 		return
 	}
 
-	// 移除哈希值中的0x前缀（如果存在）
-	if strings.HasPrefix(hash, "0x") {
-		hash = hash[2:]
+	// 同一内容存在于多个项目时，使用第一个允许访问的项目
+	var (
+		loc    *models.FileLocation
+		public bool
+	)
+	for _, candidate := range locs {
+		public, err = authorizeFile(c, candidate)
+		if err == nil {
+			loc = candidate
+			break
+		}
+		if !errors.Is(err, service.ErrUnauthenticated) && !errors.Is(err, service.ErrInvalidDownloadURL) &&
+			!errors.Is(err, service.ErrAccessDenied) {
+			break
+		}
+	}
+	switch {
+	case errors.Is(err, service.ErrUnauthenticated), errors.Is(err, service.ErrInvalidDownloadURL):
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "需要登录或有效的下载链接",
+			"details": err.Error(),
+		})
+		return
+	case errors.Is(err, service.ErrAccessDenied):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "无权下载该文件",
+			"details": err.Error(),
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to check file access",
+			"details": err.Error(),
+		})
+		return
 	}
 
-	// 查找包含该哈希值的文件
-	filePath, err := findFileByHash(hash)
-	if err != nil {
+	reader, info, err := service.OpenFile(c.Request.Context(), loc)
+	if errors.Is(err, service.ErrFileNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error":   "File not found",
 			"details": err.Error(),
 		})
 		return
 	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid file request",
+			"details": err.Error(),
+		})
+		return
+	}
+	defer reader.Close()
+
+	// 非公开的文件不允许共享缓存
+	if !public {
+		c.Header("Cache-Control", "private, no-store")
+	}
+	// 按记录的类型提供文件，不允许浏览器再根据内容猜测类型
+	c.Header("X-Content-Type-Options", "nosniff")
 
 	// 提供文件下载
-	c.File(filePath)
+	contentType := info.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	c.DataFromReader(http.StatusOK, info.Size, contentType, reader, nil)
 }
 
-// findFileByHash 根据哈希值查找文件
-func findFileByHash(hash string) (string, error) {
-	// 获取当前工作目录（程序运行所在目录）
-	cwd, err := os.Getwd()
-	if err != nil {
-		return "", fmt.Errorf("failed to get current working directory: %w", err)
+// authorizeFile 检查请求能否下载loc，返回文件所在项目是否公开
+// 携带签名的下载链接不需要登录，否则按项目的可见性检查已登录地址
+func authorizeFile(c *gin.Context, loc *models.FileLocation) (bool, error) {
+	if sig := c.Query("sig"); sig != "" {
+		return false, service.VerifyDownloadURL(loc, c.Query("expires"), sig)
 	}
+	address, _ := SessionAddress(c)
+	access, err := service.CheckFileAccess(c.Request.Context(), loc, address)
+	return access != nil && access.Visibility == models.VisibilityPublic, err
+}
 
-	// 直接在程序运行目录下的uploads目录查找
-	uploadsDir := filepath.Join(cwd, "uploads")
-
-	var foundFilePath string
-	err = filepath.Walk(uploadsDir, func(path string, info os.FileInfo, err error) error {
+// readUploadFields 读取上传请求中文件之前的表单字段，返回字段和按顺序读取文件的UploadParts
+// 字段总大小不能超过service.MaxUploadFieldsSize()
+func readUploadFields(reader *multipart.Reader) (url.Values, *multipartFiles, error) {
+	fields := make(url.Values)
+	budget := service.MaxUploadFieldsSize()
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return fields, &multipartFiles{reader: reader, done: true}, nil
+		}
 		if err != nil {
-			return err
+			return nil, nil, err
+		}
+		if part.FileName() != "" {
+			return fields, &multipartFiles{reader: reader, next: part}, nil
 		}
 
-		// 跳过目录
-		if info.IsDir() {
-			return nil
+		value, err := io.ReadAll(io.LimitReader(part, budget+1))
+		if err != nil {
+			return nil, nil, err
 		}
+		if int64(len(value)) > budget {
+			return nil, nil, fmt.Errorf("表单字段超过 %d 字节的上限", service.MaxUploadFieldsSize())
+		}
+		budget -= int64(len(value))
+		fields.Add(part.FormName(), string(value))
+	}
+}
 
-		// 检查文件名是否以哈希值开头（可能包含扩展名）
-		// 文件名格式: 哈希值.扩展名
-		prefixMatch := strings.HasPrefix(info.Name(), hash)
+// multipartFiles 从请求体中按顺序读取files字段的文件，实现service.UploadParts
+type multipartFiles struct {
+	reader *multipart.Reader
+	// next 读取表单字段时已读到的第一个文件
+	next *multipart.Part
+	done bool
+}
 
-		if prefixMatch {
-			// 检查完整哈希的匹配
-			foundFilePath = path
-			return filepath.SkipDir // 找到第一个匹配的文件后停止遍历
+// Next 返回下一个文件，其他字段名的文件被跳过，文件之后出现表单字段时返回错误
+func (m *multipartFiles) Next() (*service.UploadPart, error) {
+	for {
+		if m.done {
+			return nil, io.EOF
+		}
+		part := m.next
+		m.next = nil
+		if part == nil {
+			var err error
+			part, err = m.reader.NextPart()
+			if errors.Is(err, io.EOF) {
+				m.done = true
+				return nil, io.EOF
+			}
+			if err != nil {
+				return nil, err
+			}
 		}
 
-		return nil
-	})
-
-	if err != nil {
-		return "", err
+		if part.FileName() == "" {
+			return nil, fmt.Errorf("表单字段 %s 须在文件之前", part.FormName())
+		}
+		if part.FormName() != "files" {
+			continue
+		}
+		return &service.UploadPart{
+			Filename:    part.FileName(),
+			ContentType: part.Header.Get("Content-Type"),
+			Body:        part,
+		}, nil
 	}
+}
 
-	if foundFilePath == "" {
-		return "", fmt.Errorf("no file found with hash: %s", hash)
+// ListProjectUploads 列出项目在指定链上的上传记录，须由项目的所有者或授权提交者登录后查看
+func ListProjectUploads(c *gin.Context) {
+	address, _ := SessionAddress(c)
+	uploads, err := service.ListProjectUploads(c.Request.Context(), address, c.Query("chainId"), c.Param("pid"))
+	switch {
+	case errors.Is(err, service.ErrAccessDenied):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "无权查看该项目的上传记录",
+			"details": err.Error(),
+		})
+		return
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to list uploads",
+			"details": err.Error(),
+		})
+		return
 	}
 
-	return foundFilePath, nil
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    uploads,
+	})
 }
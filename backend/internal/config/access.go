@@ -0,0 +1,101 @@
+package config
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// 文件下载访问控制相关的环境变量
+const (
+	envDefaultVisibility = "ORACLE_DEFAULT_VISIBILITY"
+	envDownloadURLSecret = "ORACLE_DOWNLOAD_URL_SECRET"
+	envDownloadURLTTL    = "ORACLE_DOWNLOAD_URL_TTL_SECONDS"
+	envDownloadURLMaxTTL = "ORACLE_DOWNLOAD_URL_MAX_TTL_SECONDS"
+)
+
+// 签名下载链接的默认有效期和默认最长有效期
+const (
+	DefaultDownloadURLTTL    = time.Hour
+	DefaultDownloadURLMaxTTL = 7 * 24 * time.Hour
+)
+
+const (
+	// defaultVisibility 未配置时项目文件对所有人公开，与引入访问控制前的行为一致
+	defaultVisibility = "public"
+	// minDownloadURLSecret 签名下载链接HMAC密钥的最小长度
+	minDownloadURLSecret = 32
+)
+
+// AccessConfig 文件下载的访问控制配置
+type AccessConfig struct {
+	// DefaultVisibility 未设置访问权限的项目使用的可见性：public、submitters或allowlist
+	DefaultVisibility string
+	// URLSecret 签名下载链接使用的HMAC密钥
+	URLSecret []byte
+	// EphemeralSecret 未配置密钥时为true，此时使用随机密钥，重启后已分享的链接失效
+	EphemeralSecret bool
+	// URLTTL 签名下载链接的默认有效期
+	URLTTL time.Duration
+	// URLMaxTTL 签名下载链接允许的最长有效期
+	URLMaxTTL time.Duration
+}
+
+// LoadAccessConfig 从环境变量加载下载访问控制配置
+// ORACLE_DEFAULT_VISIBILITY: 未设置访问权限的项目的可见性，默认为public
+// ORACLE_DOWNLOAD_URL_SECRET: 签名下载链接的HMAC密钥（至少32字节），未配置时每次启动随机生成
+// ORACLE_DOWNLOAD_URL_TTL_SECONDS: 签名下载链接的默认有效期（秒）
+// ORACLE_DOWNLOAD_URL_MAX_TTL_SECONDS: 签名下载链接允许的最长有效期（秒）
+func LoadAccessConfig() (*AccessConfig, error) {
+	cfg := &AccessConfig{
+		DefaultVisibility: defaultVisibility,
+		URLTTL:            DefaultDownloadURLTTL,
+		URLMaxTTL:         DefaultDownloadURLMaxTTL,
+	}
+
+	if value := os.Getenv(envDefaultVisibility); value != "" {
+		switch value {
+		case "public", "submitters", "allowlist":
+			cfg.DefaultVisibility = value
+		default:
+			return nil, fmt.Errorf("invalid %s: %q (expected public, submitters or allowlist)", envDefaultVisibility, value)
+		}
+	}
+
+	if secret := os.Getenv(envDownloadURLSecret); secret != "" {
+		if len(secret) < minDownloadURLSecret {
+			return nil, fmt.Errorf("%s must be at least %d bytes", envDownloadURLSecret, minDownloadURLSecret)
+		}
+		cfg.URLSecret = []byte(secret)
+	} else {
+		cfg.URLSecret = make([]byte, minDownloadURLSecret)
+		if _, err := rand.Read(cfg.URLSecret); err != nil {
+			return nil, fmt.Errorf("failed to generate download url secret: %w", err)
+		}
+		cfg.EphemeralSecret = true
+	}
+
+	for _, d := range []struct {
+		env   string
+		value *time.Duration
+	}{
+		{envDownloadURLTTL, &cfg.URLTTL},
+		{envDownloadURLMaxTTL, &cfg.URLMaxTTL},
+	} {
+		value := os.Getenv(d.env)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", d.env, err)
+		}
+		*d.value = time.Duration(n) * time.Second
+	}
+	if cfg.URLTTL <= 0 || cfg.URLMaxTTL < cfg.URLTTL {
+		return nil, fmt.Errorf("%s must be positive and not exceed %s", envDownloadURLTTL, envDownloadURLMaxTTL)
+	}
+	return cfg, nil
+}
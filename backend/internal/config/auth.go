@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// SIWE登录相关的环境变量
+const (
+	envAuthDomains    = "ORACLE_AUTH_DOMAINS"
+	envAuthSessionTTL = "ORACLE_AUTH_SESSION_TTL_SECONDS"
+	envAuthNonceTTL   = "ORACLE_AUTH_NONCE_TTL_SECONDS"
+	envAPIKeyMaxTTL   = "ORACLE_API_KEY_MAX_TTL_SECONDS"
+)
+
+// 登录会话、登录nonce的默认有效期和API密钥的默认最长有效期
+const (
+	DefaultSessionTTL   = time.Hour
+	DefaultAuthNonceTTL = 5 * time.Minute
+	DefaultAPIKeyMaxTTL = 90 * 24 * time.Hour
+)
+
+// AuthConfig SIWE（EIP-4361）登录的配置
+type AuthConfig struct {
+	// Domains 允许出现在SIWE消息中的域名（含端口），为空时只接受与请求Host一致的域名
+	Domains []string
+	// SessionTTL 登录会话的有效期
+	SessionTTL time.Duration
+	// NonceTTL 登录nonce的有效期
+	NonceTTL time.Duration
+	// APIKeyMaxTTL API密钥允许的最长有效期，创建时未指定有效期的密钥使用该值
+	APIKeyMaxTTL time.Duration
+}
+
+// DefaultAuthConfig 返回默认的登录配置
+func DefaultAuthConfig() *AuthConfig {
+	return &AuthConfig{
+		SessionTTL:   DefaultSessionTTL,
+		NonceTTL:     DefaultAuthNonceTTL,
+		APIKeyMaxTTL: DefaultAPIKeyMaxTTL,
+	}
+}
+
+// LoadAuthConfig 从环境变量加载登录配置
+// ORACLE_AUTH_DOMAINS: 允许的SIWE域名，逗号分隔（如oracle.example.com,localhost:3000）
+// ORACLE_AUTH_SESSION_TTL_SECONDS: 登录会话的有效期（秒）
+// ORACLE_AUTH_NONCE_TTL_SECONDS: 登录nonce的有效期（秒）
+// ORACLE_API_KEY_MAX_TTL_SECONDS: API密钥允许的最长有效期（秒）
+func LoadAuthConfig() (*AuthConfig, error) {
+	cfg := DefaultAuthConfig()
+	cfg.Domains = splitList(os.Getenv(envAuthDomains))
+
+	for _, d := range []struct {
+		env   string
+		value *time.Duration
+	}{
+		{envAuthSessionTTL, &cfg.SessionTTL},
+		{envAuthNonceTTL, &cfg.NonceTTL},
+		{envAPIKeyMaxTTL, &cfg.APIKeyMaxTTL},
+	} {
+		value := os.Getenv(d.env)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", d.env, err)
+		}
+		*d.value = time.Duration(n) * time.Second
+	}
+	if cfg.SessionTTL <= 0 || cfg.NonceTTL <= 0 || cfg.APIKeyMaxTTL <= 0 {
+		return nil, fmt.Errorf("%s, %s and %s must be positive", envAuthSessionTTL, envAuthNonceTTL, envAPIKeyMaxTTL)
+	}
+	return cfg, nil
+}
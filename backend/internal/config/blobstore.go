@@ -0,0 +1,117 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// 文件存储相关的环境变量
+const (
+	envStorageBackend = "ORACLE_STORAGE_BACKEND"
+	envUploadsDir     = "ORACLE_UPLOADS_DIR"
+	envS3Endpoint     = "ORACLE_S3_ENDPOINT"
+	envS3Region       = "ORACLE_S3_REGION"
+	envS3Bucket       = "ORACLE_S3_BUCKET"
+	envS3Prefix       = "ORACLE_S3_PREFIX"
+	envS3AccessKey    = "ORACLE_S3_ACCESS_KEY"
+	envS3SecretKey    = "ORACLE_S3_SECRET_KEY"
+	envS3PathStyle    = "ORACLE_S3_PATH_STYLE"
+)
+
+// 支持的文件存储后端
+const (
+	StorageBackendLocal = "local"
+	StorageBackendS3    = "s3"
+)
+
+// defaultUploadsDir 本地存储的默认目录（相对于程序运行目录，兼容已有的上传文件）
+const defaultUploadsDir = "uploads"
+
+// DefaultS3Region 未配置时使用的S3区域
+const DefaultS3Region = "us-east-1"
+
+// BlobStoreConfig 上传文件的存储配置
+type BlobStoreConfig struct {
+	// Backend 存储后端：local或s3
+	Backend string
+	// LocalDir 本地存储的根目录（绝对路径）
+	LocalDir string
+	// S3 S3兼容存储的配置，Backend为s3时有效
+	S3 S3Config
+}
+
+// S3Config S3兼容对象存储的配置（AWS S3、MinIO等）
+type S3Config struct {
+	// Endpoint 服务地址，如https://s3.us-east-1.amazonaws.com或http://127.0.0.1:9000
+	Endpoint string
+	Region   string
+	Bucket   string
+	// Prefix 对象key的公共前缀
+	Prefix    string
+	AccessKey string
+	SecretKey string
+	// PathStyle 使用路径风格的URL（<endpoint>/<bucket>/<key>），MinIO等自建服务通常需要开启
+	PathStyle bool
+}
+
+// LoadBlobStoreConfig 从环境变量加载存储配置
+// ORACLE_STORAGE_BACKEND: local（默认）或s3
+// ORACLE_UPLOADS_DIR: 本地存储目录，默认为程序运行目录下的uploads
+// ORACLE_S3_ENDPOINT / ORACLE_S3_REGION / ORACLE_S3_BUCKET / ORACLE_S3_PREFIX: S3服务地址、区域、bucket和key前缀
+// ORACLE_S3_ACCESS_KEY / ORACLE_S3_SECRET_KEY: S3访问密钥
+// ORACLE_S3_PATH_STYLE: 是否使用路径风格的URL，默认为true
+func LoadBlobStoreConfig() (*BlobStoreConfig, error) {
+	cfg := &BlobStoreConfig{
+		Backend: os.Getenv(envStorageBackend),
+		S3: S3Config{
+			Endpoint:  os.Getenv(envS3Endpoint),
+			Region:    os.Getenv(envS3Region),
+			Bucket:    os.Getenv(envS3Bucket),
+			Prefix:    os.Getenv(envS3Prefix),
+			AccessKey: os.Getenv(envS3AccessKey),
+			SecretKey: os.Getenv(envS3SecretKey),
+			PathStyle: true,
+		},
+	}
+	if cfg.Backend == "" {
+		cfg.Backend = StorageBackendLocal
+	}
+
+	dir := os.Getenv(envUploadsDir)
+	if dir == "" {
+		dir = defaultUploadsDir
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", envUploadsDir, err)
+	}
+	cfg.LocalDir = absDir
+
+	if value := os.Getenv(envS3PathStyle); value != "" {
+		pathStyle, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", envS3PathStyle, err)
+		}
+		cfg.S3.PathStyle = pathStyle
+	}
+	if cfg.S3.Region == "" {
+		cfg.S3.Region = DefaultS3Region
+	}
+
+	switch cfg.Backend {
+	case StorageBackendLocal:
+	case StorageBackendS3:
+		if cfg.S3.Endpoint == "" || cfg.S3.Bucket == "" {
+			return nil, fmt.Errorf("%s and %s are required for the s3 storage backend", envS3Endpoint, envS3Bucket)
+		}
+		if cfg.S3.AccessKey == "" || cfg.S3.SecretKey == "" {
+			return nil, fmt.Errorf("%s and %s are required for the s3 storage backend", envS3AccessKey, envS3SecretKey)
+		}
+	default:
+		return nil, fmt.Errorf("invalid %s: %q", envStorageBackend, cfg.Backend)
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,323 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/goccy/go-yaml"
+)
+
+// DefaultChainsFile 默认的链配置文件路径（相对于程序运行目录）
+const DefaultChainsFile = "config/chains.yaml"
+
+// 环境变量名称
+const (
+	envChainsFile     = "ORACLE_CHAINS_FILE"
+	envDefaultChainID = "ORACLE_DEFAULT_CHAIN_ID"
+	envChainPrefix    = "ORACLE_CHAIN_"
+)
+
+// DefaultCallTimeout 未配置超时时RPC调用的默认超时时间
+const DefaultCallTimeout = 10 * time.Second
+
+// DefaultLogBatchSize 未配置时每次查询日志的区块范围
+const DefaultLogBatchSize = 2000
+
+// DefaultPollInterval 未配置时事件索引的轮询间隔
+const DefaultPollInterval = 15 * time.Second
+
+// DefaultProjectCacheTTL 未配置时项目目录查询结果的缓存时间
+const DefaultProjectCacheTTL = time.Minute
+
+// ErrUnknownChain 请求的链ID未在注册表中配置
+var ErrUnknownChain = errors.New("unknown chain id")
+
+// ChainConfig 单条链的配置
+type ChainConfig struct {
+	ChainID         uint64   `yaml:"chainId" json:"chainId"`
+	Name            string   `yaml:"name" json:"name"`
+	RPCURLs         []string `yaml:"rpcUrls" json:"rpcUrls"`
+	ContractAddress string   `yaml:"contractAddress" json:"contractAddress"`
+	// Confirmations 认为区块已确定所需的确认数
+	Confirmations uint64 `yaml:"confirmations" json:"confirmations"`
+	// CallTimeoutSeconds 单次RPC调用的超时时间（秒），为0时使用DefaultCallTimeout
+	CallTimeoutSeconds uint64 `yaml:"callTimeoutSeconds" json:"callTimeoutSeconds"`
+	// StartBlock 事件索引的起始区块（一般为合约部署区块）
+	StartBlock uint64 `yaml:"startBlock" json:"startBlock"`
+	// LogBatchSize 每次查询日志的区块范围，为0时使用DefaultLogBatchSize
+	LogBatchSize uint64 `yaml:"logBatchSize" json:"logBatchSize"`
+	// PollIntervalSeconds 事件索引的轮询间隔（秒），为0时使用DefaultPollInterval
+	PollIntervalSeconds uint64 `yaml:"pollIntervalSeconds" json:"pollIntervalSeconds"`
+	// ProjectCacheSeconds 项目目录（项目列表、项目配置）的缓存时间（秒），为0时使用DefaultProjectCacheTTL
+	ProjectCacheSeconds uint64 `yaml:"projectCacheSeconds" json:"projectCacheSeconds"`
+}
+
+// chainsFile 配置文件的结构
+type chainsFile struct {
+	DefaultChainID uint64        `yaml:"defaultChainId" json:"defaultChainId"`
+	Chains         []ChainConfig `yaml:"chains" json:"chains"`
+}
+
+// ChainRegistry 链ID到链配置的映射
+type ChainRegistry struct {
+	defaultChainID uint64
+	chains         map[uint64]*ChainConfig
+}
+
+// LoadChainRegistry 从配置文件和环境变量加载链注册表
+// path: 配置文件路径，为空时使用环境变量ORACLE_CHAINS_FILE或DefaultChainsFile
+// 显式指定的文件不存在时返回错误，默认文件不存在时仅使用环境变量
+func LoadChainRegistry(path string) (*ChainRegistry, error) {
+	explicit := true
+	if path == "" {
+		path = os.Getenv(envChainsFile)
+	}
+	if path == "" {
+		path = DefaultChainsFile
+		explicit = false
+	}
+
+	var file chainsFile
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := unmarshalChainsFile(path, data, &file); err != nil {
+			return nil, err
+		}
+	case os.IsNotExist(err) && !explicit:
+		// 默认配置文件不存在，只使用环境变量
+	default:
+		return nil, fmt.Errorf("failed to read chains config %s: %w", path, err)
+	}
+
+	registry := &ChainRegistry{
+		defaultChainID: file.DefaultChainID,
+		chains:         make(map[uint64]*ChainConfig),
+	}
+	for i := range file.Chains {
+		chain := file.Chains[i]
+		if _, exists := registry.chains[chain.ChainID]; exists {
+			return nil, fmt.Errorf("duplicate chain id %d in %s", chain.ChainID, path)
+		}
+		registry.chains[chain.ChainID] = &chain
+	}
+
+	if err := registry.applyEnv(os.Environ()); err != nil {
+		return nil, err
+	}
+
+	for _, chain := range registry.chains {
+		if err := chain.validate(); err != nil {
+			return nil, err
+		}
+	}
+	if registry.defaultChainID != 0 {
+		if _, ok := registry.chains[registry.defaultChainID]; !ok {
+			return nil, fmt.Errorf("default chain id %d is not configured", registry.defaultChainID)
+		}
+	}
+
+	return registry, nil
+}
+
+// unmarshalChainsFile 根据文件扩展名解析JSON或YAML配置
+func unmarshalChainsFile(path string, data []byte, file *chainsFile) error {
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, file)
+	default:
+		err = yaml.Unmarshal(data, file)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse chains config %s: %w", path, err)
+	}
+	return nil
+}
+
+// applyEnv 使用环境变量覆盖或追加链配置
+// 支持的变量：ORACLE_DEFAULT_CHAIN_ID、ORACLE_CHAIN_<ID>_RPC_URLS（逗号分隔）、
+// ORACLE_CHAIN_<ID>_CONTRACT_ADDRESS、ORACLE_CHAIN_<ID>_CONFIRMATIONS、
+// ORACLE_CHAIN_<ID>_CALL_TIMEOUT_SECONDS、ORACLE_CHAIN_<ID>_START_BLOCK、ORACLE_CHAIN_<ID>_NAME、
+// ORACLE_CHAIN_<ID>_PROJECT_CACHE_SECONDS
+func (r *ChainRegistry) applyEnv(environ []string) error {
+	for _, kv := range environ {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok {
+			continue
+		}
+
+		if key == envDefaultChainID {
+			id, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", envDefaultChainID, err)
+			}
+			r.defaultChainID = id
+			continue
+		}
+
+		if !strings.HasPrefix(key, envChainPrefix) {
+			continue
+		}
+		idStr, field, ok := strings.Cut(strings.TrimPrefix(key, envChainPrefix), "_")
+		if !ok {
+			continue
+		}
+		id, err := strconv.ParseUint(idStr, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		chain, exists := r.chains[id]
+		if !exists {
+			chain = &ChainConfig{ChainID: id}
+			r.chains[id] = chain
+		}
+
+		switch field {
+		case "RPC_URLS":
+			chain.RPCURLs = splitList(value)
+		case "CONTRACT_ADDRESS":
+			chain.ContractAddress = value
+		case "CONFIRMATIONS":
+			n, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", key, err)
+			}
+			chain.Confirmations = n
+		case "CALL_TIMEOUT_SECONDS":
+			n, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", key, err)
+			}
+			chain.CallTimeoutSeconds = n
+		case "START_BLOCK":
+			n, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", key, err)
+			}
+			chain.StartBlock = n
+		case "PROJECT_CACHE_SECONDS":
+			n, err := strconv.ParseUint(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", key, err)
+			}
+			chain.ProjectCacheSeconds = n
+		case "NAME":
+			chain.Name = value
+		}
+	}
+	return nil
+}
+
+// splitList 拆分逗号分隔的列表并去除空项
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// validate 检查链配置是否完整
+func (c *ChainConfig) validate() error {
+	if c.ChainID == 0 {
+		return errors.New("chain id must be greater than 0")
+	}
+	if len(c.RPCURLs) == 0 {
+		return fmt.Errorf("chain %d: at least one rpc url is required", c.ChainID)
+	}
+	if !common.IsHexAddress(c.ContractAddress) {
+		return fmt.Errorf("chain %d: invalid contract address %q", c.ChainID, c.ContractAddress)
+	}
+	return nil
+}
+
+// Address 返回合约地址
+func (c *ChainConfig) Address() common.Address {
+	return common.HexToAddress(c.ContractAddress)
+}
+
+// CallTimeout 返回单次RPC调用的超时时间
+func (c *ChainConfig) CallTimeout() time.Duration {
+	if c.CallTimeoutSeconds == 0 {
+		return DefaultCallTimeout
+	}
+	return time.Duration(c.CallTimeoutSeconds) * time.Second
+}
+
+// BatchSize 返回每次查询日志的区块范围
+func (c *ChainConfig) BatchSize() uint64 {
+	if c.LogBatchSize == 0 {
+		return DefaultLogBatchSize
+	}
+	return c.LogBatchSize
+}
+
+// PollInterval 返回事件索引的轮询间隔
+func (c *ChainConfig) PollInterval() time.Duration {
+	if c.PollIntervalSeconds == 0 {
+		return DefaultPollInterval
+	}
+	return time.Duration(c.PollIntervalSeconds) * time.Second
+}
+
+// Get 根据链ID获取链配置
+func (r *ChainRegistry) Get(chainID uint64) (*ChainConfig, error) {
+	chain, ok := r.chains[chainID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownChain, chainID)
+	}
+	return chain, nil
+}
+
+// Resolve 根据客户端提交的链ID字符串（十进制）获取链配置
+// 为空时使用默认链，未配置默认链时返回错误
+func (r *ChainRegistry) Resolve(chainIDStr string) (*ChainConfig, error) {
+	chainIDStr = strings.TrimSpace(chainIDStr)
+	if chainIDStr == "" {
+		if r.defaultChainID == 0 {
+			return nil, fmt.Errorf("%w: chain id is required", ErrUnknownChain)
+		}
+		return r.Get(r.defaultChainID)
+	}
+
+	chainID, err := strconv.ParseUint(chainIDStr, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownChain, chainIDStr)
+	}
+	return r.Get(chainID)
+}
+
+// ChainIDs 返回所有已配置的链ID（升序）
+func (r *ChainRegistry) ChainIDs() []uint64 {
+	ids := make([]uint64, 0, len(r.chains))
+	for id := range r.chains {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// DefaultChainID 返回默认链ID，未配置时为0
+func (r *ChainRegistry) DefaultChainID() uint64 {
+	return r.defaultChainID
+}
+
+// ProjectCacheTTL 返回项目目录查询结果的缓存时间
+func (c *ChainConfig) ProjectCacheTTL() time.Duration {
+	if c.ProjectCacheSeconds == 0 {
+		return DefaultProjectCacheTTL
+	}
+	return time.Duration(c.ProjectCacheSeconds) * time.Second
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 中继模式相关的环境变量
+const (
+	envRelayerKeystore       = "ORACLE_RELAYER_KEYSTORE"
+	envRelayerPassword       = "ORACLE_RELAYER_PASSWORD"
+	envRelayerPasswordFile   = "ORACLE_RELAYER_PASSWORD_FILE"
+	envRelayerReceiptTimeout = "ORACLE_RELAYER_RECEIPT_TIMEOUT_SECONDS"
+)
+
+// DefaultReceiptTimeout 等待交易回执的默认超时时间
+const DefaultReceiptTimeout = 5 * time.Minute
+
+// RelayerConfig 中继模式配置：后端使用本地keystore中的提交者私钥代为发送交易
+type RelayerConfig struct {
+	// KeystoreFile keystore文件路径，为空时不启用中继模式
+	KeystoreFile string
+	// Password keystore文件的解密密码
+	Password string
+	// ReceiptTimeout 等待交易回执的超时时间
+	ReceiptTimeout time.Duration
+}
+
+// Enabled 是否启用中继模式
+func (c *RelayerConfig) Enabled() bool {
+	return c.KeystoreFile != ""
+}
+
+// LoadRelayerConfig 从环境变量加载中继模式配置
+// ORACLE_RELAYER_KEYSTORE: keystore文件路径
+// ORACLE_RELAYER_PASSWORD / ORACLE_RELAYER_PASSWORD_FILE: 解密密码或密码文件
+// ORACLE_RELAYER_RECEIPT_TIMEOUT_SECONDS: 等待交易回执的超时时间（秒）
+func LoadRelayerConfig() (*RelayerConfig, error) {
+	cfg := &RelayerConfig{
+		KeystoreFile:   os.Getenv(envRelayerKeystore),
+		Password:       os.Getenv(envRelayerPassword),
+		ReceiptTimeout: DefaultReceiptTimeout,
+	}
+
+	if path := os.Getenv(envRelayerPasswordFile); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read relayer password file: %w", err)
+		}
+		cfg.Password = strings.TrimRight(string(data), "\r\n")
+	}
+
+	if value := os.Getenv(envRelayerReceiptTimeout); value != "" {
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", envRelayerReceiptTimeout, err)
+		}
+		cfg.ReceiptTimeout = time.Duration(n) * time.Second
+	}
+
+	return cfg, nil
+}
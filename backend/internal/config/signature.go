@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// 签名验证相关的环境变量
+const (
+	envAllowPersonalSign = "ORACLE_ALLOW_PERSONAL_SIGN"
+	envSignatureWindow   = "ORACLE_SIGNATURE_WINDOW_SECONDS"
+	envSignatureMaxSkew  = "ORACLE_SIGNATURE_MAX_SKEW_SECONDS"
+	envNonceTTL          = "ORACLE_NONCE_TTL_SECONDS"
+)
+
+// 签名时间戳和nonce的默认配置
+const (
+	DefaultSignatureWindow = 5 * time.Minute
+	DefaultMaxClockSkew    = 30 * time.Second
+	DefaultNonceTTL        = 10 * time.Minute
+)
+
+// SignatureConfig 上传签名的验证配置
+type SignatureConfig struct {
+	// AllowPersonalSign 是否接受对签名数据JSON字符串的personal_sign签名，默认只接受EIP-712签名
+	AllowPersonalSign bool
+	// Window 签名时间戳的有效期
+	Window time.Duration
+	// MaxClockSkew 允许签名时间戳超前于服务器时间的最大值
+	MaxClockSkew time.Duration
+	// NonceTTL 服务端签发的nonce的有效期
+	NonceTTL time.Duration
+}
+
+// DefaultSignatureConfig 返回默认的签名验证配置
+func DefaultSignatureConfig() *SignatureConfig {
+	return &SignatureConfig{
+		Window:       DefaultSignatureWindow,
+		MaxClockSkew: DefaultMaxClockSkew,
+		NonceTTL:     DefaultNonceTTL,
+	}
+}
+
+// LoadSignatureConfig 从环境变量加载签名验证配置
+// ORACLE_ALLOW_PERSONAL_SIGN: 为true时同时接受personal_sign签名，签名数据仍须包含chainId和nonce
+// ORACLE_SIGNATURE_WINDOW_SECONDS: 签名时间戳的有效期（秒）
+// ORACLE_SIGNATURE_MAX_SKEW_SECONDS: 允许签名时间戳超前于服务器时间的秒数
+// ORACLE_NONCE_TTL_SECONDS: 服务端签发的nonce的有效期（秒）
+func LoadSignatureConfig() (*SignatureConfig, error) {
+	cfg := DefaultSignatureConfig()
+	if value := os.Getenv(envAllowPersonalSign); value != "" {
+		allow, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", envAllowPersonalSign, err)
+		}
+		cfg.AllowPersonalSign = allow
+	}
+
+	for _, d := range []struct {
+		env   string
+		value *time.Duration
+	}{
+		{envSignatureWindow, &cfg.Window},
+		{envSignatureMaxSkew, &cfg.MaxClockSkew},
+		{envNonceTTL, &cfg.NonceTTL},
+	} {
+		value := os.Getenv(d.env)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", d.env, err)
+		}
+		*d.value = time.Duration(n) * time.Second
+	}
+	if cfg.Window <= 0 || cfg.NonceTTL <= 0 {
+		return nil, fmt.Errorf("%s and %s must be positive", envSignatureWindow, envNonceTTL)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	envDataDir       = "ORACLE_DATA_DIR"
+	envIndexer       = "ORACLE_INDEXER"
+	envReconcile     = "ORACLE_RECONCILE_INTERVAL_MINUTES"
+	defaultDataDir   = "data"
+	databaseFileName = "oracle.db"
+	stagingDirName   = "staging"
+)
+
+// DataDir 返回后端状态数据的存放目录（环境变量ORACLE_DATA_DIR，默认为程序运行目录下的data）
+func DataDir() string {
+	if dir := os.Getenv(envDataDir); dir != "" {
+		return dir
+	}
+	return defaultDataDir
+}
+
+// DatabasePath 返回本地数据库文件路径
+func DatabasePath() string {
+	return filepath.Join(DataDir(), databaseFileName)
+}
+
+// StagingDir 返回上传文件在提交到存储后端之前的临时目录
+func StagingDir() string {
+	return filepath.Join(DataDir(), stagingDirName)
+}
+
+// IndexerEnabled 是否启动链上事件索引（环境变量ORACLE_INDEXER=on时开启，默认关闭）
+// 开启前须为每条链配置startBlock（合约部署区块），否则索引器会从创世区块开始扫描
+// 索引器按事件所在区块读取记录内容，RPC节点须保留startBlock之后的历史状态（归档节点）
+func IndexerEnabled() bool {
+	switch strings.ToLower(os.Getenv(envIndexer)) {
+	case "on", "true", "1":
+		return true
+	}
+	return false
+}
+
+// DefaultReconcileInterval 默认的对账任务执行间隔
+const DefaultReconcileInterval = time.Hour
+
+// ReconcileInterval 返回对账任务的执行间隔（环境变量ORACLE_RECONCILE_INTERVAL_MINUTES，为0时关闭）
+func ReconcileInterval() (time.Duration, error) {
+	value := os.Getenv(envReconcile)
+	if value == "" {
+		return DefaultReconcileInterval, nil
+	}
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", envReconcile, err)
+	}
+	return time.Duration(n) * time.Minute, nil
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// 上传文件限制相关的环境变量
+const (
+	envUploadAllowedTypes = "ORACLE_UPLOAD_ALLOWED_TYPES"
+	envUploadMaxFileSize  = "ORACLE_UPLOAD_MAX_FILE_BYTES"
+	envUploadMaxFiles     = "ORACLE_UPLOAD_MAX_FILES"
+	envUploadMaxTotalSize = "ORACLE_UPLOAD_MAX_TOTAL_BYTES"
+)
+
+// 上传文件的默认限制
+const (
+	DefaultUploadMaxFileSize  = 100 << 20
+	DefaultUploadMaxFiles     = 20
+	DefaultUploadMaxTotalSize = 500 << 20
+)
+
+// UploadLimits 服务端的上传文件限制，既是项目未设置上传策略时的默认值，也是项目策略允许的上限
+type UploadLimits struct {
+	// AllowedTypes 允许的MIME类型（如text/csv、image/*），为空时不限制类型
+	AllowedTypes []string
+	// MaxFileSize 单个文件的最大字节数
+	MaxFileSize int64
+	// MaxFiles 一次提交的最大文件数
+	MaxFiles int
+	// MaxTotalSize 一次提交的文件总字节数上限
+	MaxTotalSize int64
+}
+
+// LoadUploadLimits 从环境变量加载上传文件限制
+// ORACLE_UPLOAD_ALLOWED_TYPES: 允许的MIME类型，逗号分隔，支持type/*通配，默认不限制
+// ORACLE_UPLOAD_MAX_FILE_BYTES: 单个文件的最大字节数，默认100MiB
+// ORACLE_UPLOAD_MAX_FILES: 一次提交的最大文件数，默认20
+// ORACLE_UPLOAD_MAX_TOTAL_BYTES: 一次提交的文件总字节数上限，默认500MiB
+func LoadUploadLimits() (*UploadLimits, error) {
+	limits := &UploadLimits{
+		AllowedTypes: splitList(os.Getenv(envUploadAllowedTypes)),
+		MaxFileSize:  DefaultUploadMaxFileSize,
+		MaxFiles:     DefaultUploadMaxFiles,
+		MaxTotalSize: DefaultUploadMaxTotalSize,
+	}
+
+	for _, l := range []struct {
+		env   string
+		value *int64
+	}{
+		{envUploadMaxFileSize, &limits.MaxFileSize},
+		{envUploadMaxTotalSize, &limits.MaxTotalSize},
+	} {
+		value := os.Getenv(l.env)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid %s: %q", l.env, value)
+		}
+		*l.value = n
+	}
+	if value := os.Getenv(envUploadMaxFiles); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid %s: %q", envUploadMaxFiles, value)
+		}
+		limits.MaxFiles = n
+	}
+	return limits, nil
+}
@@ -0,0 +1,218 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"oracle-backend/internal/config"
+	"oracle-backend/internal/models"
+	"oracle-backend/internal/service"
+	"oracle-backend/internal/store"
+)
+
+// minRewindDepth 检测到链重组时至少回滚的区块数
+const minRewindDepth = 64
+
+// Indexer 跟踪单条链上的DataSubmitted和ProjectRegistered事件，并写入本地索引
+// 只处理达到确认数的区块；每批处理后保存进度，重启后从进度继续
+type Indexer struct {
+	chain  *config.ChainConfig
+	client *service.OracleClient
+	store  *store.Store
+}
+
+// New 创建单条链的索引器
+func New(chain *config.ChainConfig, client *service.OracleClient, st *store.Store) *Indexer {
+	return &Indexer{
+		chain:  chain,
+		client: client,
+		store:  st,
+	}
+}
+
+// Start 为注册表中的每条链启动索引器，ctx取消时停止
+// 每条链都必须配置startBlock（合约部署区块），否则索引器会从创世区块开始扫描
+func Start(ctx context.Context, registry *config.ChainRegistry, pool *service.ClientPool, st *store.Store) error {
+	for _, chainID := range registry.ChainIDs() {
+		chain, err := registry.Get(chainID)
+		if err != nil {
+			return err
+		}
+		if chain.StartBlock == 0 {
+			return fmt.Errorf("chain %d: set startBlock to the contract deployment block before enabling the indexer", chainID)
+		}
+		client, err := pool.Get(chainID)
+		if err != nil {
+			return err
+		}
+		go New(chain, client, st).Run(ctx)
+	}
+	return nil
+}
+
+// Run 按轮询间隔持续索引，直到ctx被取消
+func (ix *Indexer) Run(ctx context.Context) {
+	log.Printf("indexer: chain %d started", ix.chain.ChainID)
+
+	ticker := time.NewTicker(ix.chain.PollInterval())
+	defer ticker.Stop()
+
+	for {
+		if err := ix.sync(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("indexer: chain %d: %v", ix.chain.ChainID, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Printf("indexer: chain %d stopped", ix.chain.ChainID)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sync 从上次的进度索引到最新的已确认区块
+func (ix *Indexer) sync(ctx context.Context) error {
+	next, err := ix.nextBlock(ctx)
+	if err != nil {
+		return err
+	}
+
+	head, err := ix.client.GetLatestBlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+	if head < ix.chain.Confirmations {
+		service.MarkIndexSynced(ix.chain.ChainID, time.Now())
+		return nil
+	}
+	safe := head - ix.chain.Confirmations
+
+	for next <= safe {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		to := next + ix.chain.BatchSize() - 1
+		if to > safe {
+			to = safe
+		}
+		if err := ix.indexRange(ctx, next, to); err != nil {
+			return err
+		}
+		next = to + 1
+	}
+	service.MarkIndexSynced(ix.chain.ChainID, time.Now())
+	return nil
+}
+
+// nextBlock 返回下一个需要索引的区块
+// 如果已保存进度对应的区块哈希与链上不一致（超过确认深度的重组），先回滚索引
+func (ix *Indexer) nextBlock(ctx context.Context) (uint64, error) {
+	cp, err := ix.store.Checkpoint(ix.chain.ChainID)
+	if err != nil {
+		return 0, err
+	}
+	if cp == nil {
+		return ix.chain.StartBlock, nil
+	}
+
+	header, err := ix.client.HeaderByNumber(ctx, cp.BlockNumber)
+	if err != nil {
+		return 0, err
+	}
+	if header.Hash().Hex() == cp.BlockHash {
+		return cp.BlockNumber + 1, nil
+	}
+
+	// 检测到链重组，回滚到足够早的区块重新索引
+	depth := ix.chain.Confirmations * 2
+	if depth < minRewindDepth {
+		depth = minRewindDepth
+	}
+	target := ix.chain.StartBlock
+	if cp.BlockNumber > target+depth {
+		target = cp.BlockNumber - depth
+	}
+
+	targetHeader, err := ix.client.HeaderByNumber(ctx, target)
+	if err != nil {
+		return 0, err
+	}
+	log.Printf("indexer: chain %d: reorg detected at block %d, rewinding to %d", ix.chain.ChainID, cp.BlockNumber, target)
+
+	err = ix.store.RewindIndex(models.IndexCheckpoint{
+		ChainID:     ix.chain.ChainID,
+		BlockNumber: target,
+		BlockHash:   targetHeader.Hash().Hex(),
+		UpdatedAt:   time.Now(),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to rewind index: %w", err)
+	}
+	return target + 1, nil
+}
+
+// indexRange 索引[from, to]区块范围内的事件并保存进度
+func (ix *Indexer) indexRange(ctx context.Context, from, to uint64) error {
+	logs, err := ix.client.FilterEvents(ctx, from, to)
+	if err != nil {
+		return err
+	}
+
+	var projects []models.IndexedProject
+	var records []models.IndexedDataRecord
+	for _, l := range logs {
+		if l.Removed {
+			continue
+		}
+
+		if event, err := ix.client.ParseDataSubmitted(l); err == nil {
+			record := models.IndexedDataRecord{
+				ChainID:     ix.chain.ChainID,
+				Pid:         service.Bytes32ToHex(event.Pid),
+				Did:         service.Bytes32ToHex(event.Did),
+				Submitter:   event.Submitter.Hex(),
+				Timestamp:   event.Timestamp.Uint64(),
+				BlockNumber: l.BlockNumber,
+				BlockHash:   l.BlockHash.Hex(),
+				TxHash:      l.TxHash.Hex(),
+				LogIndex:    l.Index,
+			}
+			// 读取记录内容失败时整批重试，读取接口使用索引时不再访问RPC节点
+			if err := service.LoadIndexedRecord(ctx, ix.client, &record); err != nil {
+				return fmt.Errorf("failed to load record %s: %w", record.Did, err)
+			}
+			records = append(records, record)
+			continue
+		}
+
+		if event, err := ix.client.ParseProjectRegistered(l); err == nil {
+			projects = append(projects, models.IndexedProject{
+				ChainID:     ix.chain.ChainID,
+				Pid:         service.Bytes32ToHex(event.Pid),
+				Submitter:   event.Submitter.Hex(),
+				Description: event.Description,
+				BlockNumber: l.BlockNumber,
+				TxHash:      l.TxHash.Hex(),
+			})
+			continue
+		}
+
+		log.Printf("indexer: chain %d: skipping unrecognized log %s#%d", ix.chain.ChainID, l.TxHash.Hex(), l.Index)
+	}
+
+	header, err := ix.client.HeaderByNumber(ctx, to)
+	if err != nil {
+		return err
+	}
+
+	return ix.store.ApplyIndexBatch(models.IndexCheckpoint{
+		ChainID:     ix.chain.ChainID,
+		BlockNumber: to,
+		BlockHash:   header.Hash().Hex(),
+		UpdatedAt:   time.Now(),
+	}, projects, records)
+}
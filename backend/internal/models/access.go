@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+)
+
+// Visibility 项目文件的下载权限
+type Visibility string
+
+const (
+	// VisibilityPublic 所有人可以下载
+	VisibilityPublic Visibility = "public"
+	// VisibilitySubmitters 只有项目的授权提交者可以下载
+	VisibilitySubmitters Visibility = "submitters"
+	// VisibilityAllowlist 授权提交者和允许列表中的地址可以下载
+	VisibilityAllowlist Visibility = "allowlist"
+)
+
+// Valid 是否为已知的可见性
+func (v Visibility) Valid() bool {
+	switch v {
+	case VisibilityPublic, VisibilitySubmitters, VisibilityAllowlist:
+		return true
+	}
+	return false
+}
+
+// ProjectAccess 项目在指定链上的文件下载权限
+type ProjectAccess struct {
+	ChainID    uint64     `json:"chainId"`
+	Pid        string     `json:"pid"`
+	Visibility Visibility `json:"visibility"`
+	// Allowlist 可见性为allowlist时额外允许下载的地址
+	Allowlist []string `json:"allowlist,omitempty"`
+	// UpdatedBy 最后修改权限的授权提交者，为空表示使用默认配置
+	UpdatedBy string    `json:"updatedBy,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt,omitempty"`
+}
+
+// UpdateProjectAccessRequest 修改项目下载权限的请求
+type UpdateProjectAccessRequest struct {
+	Visibility Visibility `json:"visibility" binding:"required"`
+	Allowlist  []string   `json:"allowlist"`
+}
+
+// DownloadURLRequest 生成签名下载链接的请求
+type DownloadURLRequest struct {
+	// TTLSeconds 链接有效期（秒），为0时使用默认有效期
+	TTLSeconds uint64 `json:"ttlSeconds"`
+}
+
+// DownloadURL 签名下载链接
+type DownloadURL struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+)
+
+// APIKey 供无钱包的程序（如ETL任务）上传数据使用的API密钥
+// 由项目的链上授权提交者登录后创建，只能用于指定的链和项目，数据库中只保存密钥的哈希
+type APIKey struct {
+	// ID 密钥的公开标识，也是密钥原文的一部分，用于查找和吊销
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	// KeyHash 密钥原文的SHA-256（十六进制），不返回给客户端
+	KeyHash string `json:"keyHash,omitempty"`
+	// Address 创建密钥的授权提交者地址，使用密钥上传时按该地址检查合约权限
+	Address  string   `json:"address"`
+	ChainIDs []uint64 `json:"chainIds"`
+	// Pids 允许上传的项目（bytes32十六进制）
+	Pids      []string   `json:"pids"`
+	CreatedAt time.Time  `json:"createdAt"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+}
+
+// CreateAPIKeyRequest 创建API密钥的请求
+type CreateAPIKeyRequest struct {
+	Name       string   `json:"name"`
+	ChainIDs   []uint64 `json:"chainIds" binding:"required"`
+	ProjectIDs []string `json:"projectIds" binding:"required"`
+	// TTLSeconds 密钥有效期（秒），为0时使用允许的最长有效期
+	TTLSeconds uint64 `json:"ttlSeconds"`
+}
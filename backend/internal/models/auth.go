@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+)
+
+// AuthNonce 签发给SIWE登录消息的一次性nonce（签发时还不知道登录地址）
+type AuthNonce struct {
+	Nonce     string    `json:"nonce"`
+	IssuedAt  time.Time `json:"issuedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Session SIWE登录后签发的会话，数据库中只保存令牌的哈希
+type Session struct {
+	// TokenHash 会话令牌的SHA-256（十六进制）
+	TokenHash string    `json:"tokenHash"`
+	Address   string    `json:"address"`
+	ChainID   uint64    `json:"chainId"`
+	IssuedAt  time.Time `json:"issuedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// SIWEMessage 解析后的EIP-4361登录消息
+type SIWEMessage struct {
+	Domain         string     `json:"domain"`
+	Address        string     `json:"address"`
+	Statement      string     `json:"statement,omitempty"`
+	URI            string     `json:"uri"`
+	Version        string     `json:"version"`
+	ChainID        uint64     `json:"chainId"`
+	Nonce          string     `json:"nonce"`
+	IssuedAt       time.Time  `json:"issuedAt"`
+	ExpirationTime *time.Time `json:"expirationTime,omitempty"`
+	NotBefore      *time.Time `json:"notBefore,omitempty"`
+	RequestID      string     `json:"requestId,omitempty"`
+	Resources      []string   `json:"resources,omitempty"`
+}
+
+// SignInRequest SIWE登录请求
+type SignInRequest struct {
+	// Message 钱包签名的EIP-4361消息原文
+	Message   string `json:"message" binding:"required"`
+	Signature string `json:"signature" binding:"required"`
+}
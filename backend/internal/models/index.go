@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+)
+
+// IndexCheckpoint 索引器在某条链上已处理到的位置
+type IndexCheckpoint struct {
+	ChainID     uint64    `json:"chainId"`
+	BlockNumber uint64    `json:"blockNumber"`
+	BlockHash   string    `json:"blockHash"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// IndexedProject 从ProjectRegistered事件索引的项目
+type IndexedProject struct {
+	ChainID     uint64 `json:"chainId"`
+	Pid         string `json:"pid"`
+	Submitter   string `json:"submitter"`
+	Description string `json:"description"`
+	BlockNumber uint64 `json:"blockNumber"`
+	TxHash      string `json:"txHash"`
+}
+
+// IndexedDataRecord 从DataSubmitted事件索引的数据记录
+// 核心数据、dataHash和解码后的日期在索引时通过getData和数据ID解码获取一次，读取接口直接使用，无需每次请求访问RPC节点
+type IndexedDataRecord struct {
+	ChainID   uint64 `json:"chainId"`
+	Pid       string `json:"pid"`
+	Did       string `json:"did"`
+	Year      uint16 `json:"year,omitempty"`
+	Month     uint8  `json:"month,omitempty"`
+	Day       uint8  `json:"day,omitempty"`
+	Submitter string `json:"submitter"`
+	Timestamp uint64 `json:"timestamp"`
+	// CoreData 0x开头的十六进制核心数据，DataHash 链上的dataHash；较早版本写入的条目为空
+	CoreData    string `json:"coreData,omitempty"`
+	DataHash    string `json:"dataHash,omitempty"`
+	BlockNumber uint64 `json:"blockNumber"`
+	BlockHash   string `json:"blockHash"`
+	TxHash      string `json:"txHash"`
+	LogIndex    uint   `json:"logIndex"`
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// OracleRecord 合约中的一条数据记录（getData/getLatestData的返回值）
+type OracleRecord struct {
+	Pid        common.Hash    `json:"pid"`
+	Did        common.Hash    `json:"did"`
+	CoreData   hexutil.Bytes  `json:"coreData"`
+	DataHash   common.Hash    `json:"dataHash"`
+	Submitter  common.Address `json:"submitter"`
+	SubmitTime *big.Int       `json:"submitTime"`
+}
+
+// ProjectConfig 合约中的项目配置（getProjectConfig的返回值）
+type ProjectConfig struct {
+	IsActive             bool             `json:"isActive"`
+	Description          string           `json:"description"`
+	AuthorizedSubmitters []common.Address `json:"authorizedSubmitters"`
+	DataTTL              *big.Int         `json:"dataTTL"`
+}
+
+// ProjectInfo 面向HTTP消费者的项目信息：项目ID及其在合约中的配置
+type ProjectInfo struct {
+	ChainID uint64 `json:"chainId"`
+	Pid     string `json:"pid"`
+	// ProjectID 按字符串解码的项目ID（前端注册的项目ID为左对齐的字符串），无法解码时为空
+	ProjectID string `json:"projectId,omitempty"`
+	ProjectConfig
+}
+
+// DataRecordView 面向HTTP消费者的数据记录：数据ID解码为日期，核心数据解码为键值对，并附带已上传文件的链接
+type DataRecordView struct {
+	ChainID uint64 `json:"chainId"`
+	Pid     string `json:"pid"`
+	Did     string `json:"did"`
+	Year    uint16 `json:"year"`
+	Month   uint8  `json:"month"`
+	Day     uint8  `json:"day"`
+
+	CoreData hexutil.Bytes `json:"coreData"`
+	// CoreDataValues 解码后的核心数据键值对，值为uint256的十进制字符串
+	CoreDataValues map[string]string `json:"coreDataValues,omitempty"`
+	// CoreDataError 核心数据不是前端序列化格式时的解码错误（如绕过前端直接调用合约提交的数据）
+	CoreDataError string `json:"coreDataError,omitempty"`
+
+	DataHash   string `json:"dataHash"`
+	Submitter  string `json:"submitter"`
+	SubmitTime uint64 `json:"submitTime"`
+
+	Files []AttachedFile `json:"files"`
+	// FilesHidden 调用者无权查看项目文件（按项目的可见性）时为true，此时files为空
+	FilesHidden bool `json:"filesHidden,omitempty"`
+}
+
+// AttachedFile 数据记录对应的已上传文件
+type AttachedFile struct {
+	FileName    string `json:"fileName"`
+	FileHash    string `json:"fileHash"`
+	FileSize    int64  `json:"fileSize"`
+	ContentType string `json:"contentType"`
+	// URL 文件的下载地址（/attach/<chainId>/<pid>/<hash>）
+	URL string `json:"url"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+)
+
+// UploadPolicy 项目在指定链上的上传文件策略，为零的项使用服务端的默认限制
+type UploadPolicy struct {
+	ChainID uint64 `json:"chainId"`
+	Pid     string `json:"pid"`
+	// AllowedTypes 允许的MIME类型（如text/csv、image/*），按文件内容识别的类型匹配
+	AllowedTypes []string `json:"allowedTypes,omitempty"`
+	MaxFileSize  int64    `json:"maxFileSize,omitempty"`
+	MaxFiles     int      `json:"maxFiles,omitempty"`
+	MaxTotalSize int64    `json:"maxTotalSize,omitempty"`
+	// UpdatedBy 最后修改策略的授权提交者，为空表示使用默认限制
+	UpdatedBy string    `json:"updatedBy,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt,omitempty"`
+}
+
+// UpdateUploadPolicyRequest 修改项目上传策略的请求，各项不能超过服务端的限制
+type UpdateUploadPolicyRequest struct {
+	AllowedTypes []string `json:"allowedTypes"`
+	MaxFileSize  int64    `json:"maxFileSize"`
+	MaxFiles     int      `json:"maxFiles"`
+	MaxTotalSize int64    `json:"maxTotalSize"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+)
+
+// SubmissionFile 一次提交中的单个文件
+type SubmissionFile struct {
+	FileName string `json:"fileName"`
+	FileHash string `json:"fileHash"`
+	// Path 相对于上传根目录的存储路径
+	Path string `json:"path"`
+}
+
+// SubmissionManifest 一次提交（项目+数据ID）与已保存文件的对应关系
+// FileHashes 保持签名数据中的顺序，用于按前端规则重新计算dataHash
+type SubmissionManifest struct {
+	ChainID    uint64           `json:"chainId"`
+	ProjectID  string           `json:"projectId"`
+	Pid        string           `json:"pid"`
+	Did        string           `json:"did"`
+	DataDate   string           `json:"dataDate"`
+	FileHashes []string         `json:"fileHashes"`
+	Files      []SubmissionFile `json:"files"`
+	// Signer 提交者地址，早期的提交记录为空（以文件元数据中的Signer为准）
+	Signer    string    `json:"signer,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ReconcileStatus 本地文件与链上dataHash的对账结果
+type ReconcileStatus string
+
+const (
+	// ReconcileMatched 本地文件完整且重新计算的dataHash与链上一致
+	ReconcileMatched ReconcileStatus = "matched"
+	// ReconcileMissingOnDisk 链上有记录但本地缺少对应文件
+	ReconcileMissingOnDisk ReconcileStatus = "missing-on-disk"
+	// ReconcileOrphanedOnDisk 本地文件没有对应的提交记录
+	ReconcileOrphanedOnDisk ReconcileStatus = "orphaned-on-disk"
+	// ReconcileMismatched 本地文件内容或重新计算的dataHash与链上不一致
+	ReconcileMismatched ReconcileStatus = "mismatched"
+)
+
+// ReconcileResult 单条数据记录的对账结果
+type ReconcileResult struct {
+	ChainID          uint64          `json:"chainId"`
+	Pid              string          `json:"pid"`
+	Did              string          `json:"did"`
+	Status           ReconcileStatus `json:"status"`
+	OnChainDataHash  string          `json:"onChainDataHash"`
+	ExpectedDataHash string          `json:"expectedDataHash,omitempty"`
+	MissingFiles     []string        `json:"missingFiles,omitempty"`
+	MismatchedFiles  []string        `json:"mismatchedFiles,omitempty"`
+	Details          string          `json:"details,omitempty"`
+}
+
+// ReconcileReport 对账任务的汇总报告
+type ReconcileReport struct {
+	StartedAt     time.Time         `json:"startedAt"`
+	FinishedAt    time.Time         `json:"finishedAt"`
+	Results       []ReconcileResult `json:"results"`
+	OrphanedFiles []string          `json:"orphanedFiles"`
+	Errors        []string          `json:"errors,omitempty"`
+}
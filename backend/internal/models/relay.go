@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+)
+
+// RelayStatus 中继交易的状态
+type RelayStatus string
+
+const (
+	// RelayStatusPending 交易已广播，等待打包
+	RelayStatusPending RelayStatus = "pending"
+	// RelayStatusMined 交易已打包，确认数不足
+	RelayStatusMined RelayStatus = "mined"
+	// RelayStatusConfirmed 交易已达到配置的确认数
+	RelayStatusConfirmed RelayStatus = "confirmed"
+	// RelayStatusFailed 交易执行失败（回执状态为0）
+	RelayStatusFailed RelayStatus = "failed"
+	// RelayStatusTimeout 在超时时间内未获取到回执
+	RelayStatusTimeout RelayStatus = "timeout"
+)
+
+// RelayedTx 后端代为发送的交易及其回执跟踪状态
+type RelayedTx struct {
+	ChainID     uint64      `json:"chainId"`
+	TxHash      string      `json:"txHash"`
+	Method      string      `json:"method"`
+	From        string      `json:"from"`
+	Nonce       uint64      `json:"nonce"`
+	Status      RelayStatus `json:"status"`
+	BlockNumber uint64      `json:"blockNumber,omitempty"`
+	GasUsed     uint64      `json:"gasUsed,omitempty"`
+	Error       string      `json:"error,omitempty"`
+	SubmittedAt time.Time   `json:"submittedAt"`
+}
+
+// BatchRelayRequest 批量中继请求：将同一条链上已上传的多条提交一次性通过batchSubmitData上链
+type BatchRelayRequest struct {
+	// ChainID 链ID，为空时使用默认链
+	ChainID     string            `json:"chainId"`
+	Submissions []BatchRelayEntry `json:"submissions" binding:"required,min=1"`
+}
+
+// BatchRelayEntry 批量中继中的一条已上传提交
+type BatchRelayEntry struct {
+	// ProjectID 项目ID（字符串或bytes32十六进制）
+	ProjectID string `json:"projectId" binding:"required"`
+	// Did 数据ID（bytes32十六进制或YYYY-MM-DD日期）
+	Did string `json:"did" binding:"required"`
+}
+
+// SubmitEntry batchSubmitData中的一条数据
+type SubmitEntry struct {
+	Pid      [32]byte
+	Did      [32]byte
+	CoreData []byte
+	DataHash [32]byte
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+)
+
+// IssuedNonce 服务端签发给地址的一次性nonce，用于上传签名
+type IssuedNonce struct {
+	Address   string    `json:"address"`
+	Nonce     string    `json:"nonce"`
+	IssuedAt  time.Time `json:"issuedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// UsedSignature 已使用过的上传签名，在签名有效期内拒绝再次使用
+type UsedSignature struct {
+	// Hash 被签名的摘要（EIP-712哈希或personal_sign消息哈希）
+	Hash      string    `json:"hash"`
+	Signer    string    `json:"signer"`
+	ChainID   uint64    `json:"chainId"`
+	UsedAt    time.Time `json:"usedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
@@ -4,13 +4,26 @@ import (
 	"time"
 )
 
+// 签名方式，对应上传表单的signatureType字段
+const (
+	// SignatureTypeEIP712 EIP-712结构化数据签名（OracleUpload），默认的签名方式
+	SignatureTypeEIP712 = "eip712"
+	// SignatureTypePersonalSign 对签名数据JSON字符串的personal_sign签名（旧版前端），须在配置中启用
+	SignatureTypePersonalSign = "personal_sign"
+)
+
 // SignatureData 签名数据结构
 type SignatureData struct {
 	ProjectID    string   `json:"projectId"`
 	DataDate     string   `json:"dataDate"`
 	CoreDataHash string   `json:"coreDataHash"`
 	FileHashes   []string `json:"fileHashes"`
-	Timestamp    int64    `json:"timestamp"`
+	// ChainID 签名时钱包所在的链，必填
+	ChainID uint64 `json:"chainId,omitempty"`
+	// Timestamp 签名时间（毫秒）
+	Timestamp int64 `json:"timestamp"`
+	// Nonce 服务端签发的nonce，十进制表示的uint256，必填
+	Nonce string `json:"nonce,omitempty"`
 }
 
 // HashResult 定义前端传递的哈希结果结构
@@ -22,14 +35,19 @@ type HashResult struct {
 
 // FileUploadResult 定义文件上传的返回结果
 type FileUploadResult struct {
-	FileName    string    `json:"file_name"`
-	FileSize    int64     `json:"file_size"`
-	FileHash    string    `json:"file_hash"`
-	FilePath    string    `json:"file_path"`
-	UploadTime  time.Time `json:"upload_time"`
-	ContentType string    `json:"content_type"`
-	Signer      string    `json:"signer"`
-	Signature   string    `json:"signature"`
+	FileName   string    `json:"file_name"`
+	FileSize   int64     `json:"file_size"`
+	FileHash   string    `json:"file_hash"`
+	FilePath   string    `json:"file_path"`
+	UploadTime time.Time `json:"upload_time"`
+	// ContentType 按文件内容识别的类型（识别结果笼统时为与之相容的声明类型），下载时使用
+	ContentType string `json:"content_type"`
+	// SniffedContentType 按文件开头的字节识别的类型
+	SniffedContentType string `json:"sniffed_content_type"`
+	// DeclaredContentType 客户端声明的类型
+	DeclaredContentType string `json:"declared_content_type"`
+	Signer              string `json:"signer"`
+	Signature           string `json:"signature"`
 }
 
 // UploadRequest 上传请求结构
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+)
+
+// UploadRecord 单个上传文件的持久化元数据
+type UploadRecord struct {
+	ChainID   uint64 `json:"chainId"`
+	ProjectID string `json:"projectId"`
+	Pid       string `json:"pid"`
+	Did       string `json:"did"`
+	DataDate  string `json:"dataDate"`
+
+	FileName    string `json:"fileName"`
+	FileSize    int64  `json:"fileSize"`
+	FileHash    string `json:"fileHash"`
+	ContentType string `json:"contentType"`
+	// SniffedContentType 按文件开头的字节识别的类型，DeclaredContentType 客户端声明的类型
+	SniffedContentType  string `json:"sniffedContentType,omitempty"`
+	DeclaredContentType string `json:"declaredContentType,omitempty"`
+	// StoragePath 相对于上传根目录的存储路径
+	StoragePath string `json:"storagePath"`
+
+	Signer        string `json:"signer"`
+	Signature     string `json:"signature"`
+	SignedPayload string `json:"signedPayload"`
+	// APIKeyID 使用API密钥上传时的密钥ID，此时Signer为密钥绑定的地址，Signature和SignedPayload为空
+	APIKeyID string `json:"apiKeyId,omitempty"`
+	// CoreData 序列化后的核心数据（0x开头的十六进制）
+	CoreData string `json:"coreData"`
+	// CoreDataValues 解码后的核心数据键值对，值为uint256的十进制字符串
+	CoreDataValues map[string]string `json:"coreDataValues,omitempty"`
+
+	UploadTime time.Time `json:"uploadTime"`
+}
+
+// FileLocation 哈希索引条目：文件内容哈希到存储位置的映射
+type FileLocation struct {
+	ChainID     uint64 `json:"chainId"`
+	Pid         string `json:"pid"`
+	FileHash    string `json:"fileHash"`
+	FileName    string `json:"fileName"`
+	ContentType string `json:"contentType"`
+	// StoragePath 相对于上传根目录的存储路径
+	StoragePath string `json:"storagePath"`
+}
+
+// FileStatus 多文件提交中单个文件的处理结果
+type FileStatus string
+
+const (
+	// FileStatusCommitted 文件已保存
+	FileStatusCommitted FileStatus = "committed"
+	// FileStatusFailed 文件本身校验或保存失败
+	FileStatusFailed FileStatus = "failed"
+	// FileStatusAborted 文件本身没有问题，但因同一提交中的其他文件失败而未保存（或已回滚）
+	FileStatusAborted FileStatus = "aborted"
+	// FileStatusMissing 签名数据中的文件未随请求上传
+	FileStatusMissing FileStatus = "missing"
+)
+
+// FileReport 多文件提交中单个文件的处理报告
+type FileReport struct {
+	FileName string     `json:"fileName,omitempty"`
+	FileHash string     `json:"fileHash,omitempty"`
+	Status   FileStatus `json:"status"`
+	Error    string     `json:"error,omitempty"`
+}
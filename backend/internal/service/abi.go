@@ -0,0 +1,45 @@
+package service
+
+// oracleABI Oracle合约的完整ABI（函数和事件），与前端 contracts/OracleABI.js 保持一致
+const oracleABI = `[
+    {"inputs":[{"internalType":"bytes32","name":"pid","type":"bytes32"},{"internalType":"address","name":"submitter","type":"address"}],"name":"addAuthorizedSubmitter","outputs":[],"stateMutability":"nonpayable","type":"function"},
+    {"inputs":[{"internalType":"bytes32[]","name":"pids","type":"bytes32[]"},{"internalType":"bytes32[]","name":"dids","type":"bytes32[]"},{"internalType":"bytes[]","name":"coreDataArray","type":"bytes[]"},{"internalType":"bytes32[]","name":"dataHashes","type":"bytes32[]"}],"name":"batchSubmitData","outputs":[],"stateMutability":"nonpayable","type":"function"},
+    {"anonymous":false,"inputs":[{"indexed":true,"internalType":"bytes32","name":"pid","type":"bytes32"},{"indexed":true,"internalType":"bytes32","name":"did","type":"bytes32"},{"indexed":true,"internalType":"address","name":"submitter","type":"address"},{"indexed":false,"internalType":"uint256","name":"timestamp","type":"uint256"}],"name":"DataSubmitted","type":"event"},
+    {"inputs":[{"internalType":"bytes32","name":"role","type":"bytes32"},{"internalType":"address","name":"account","type":"address"}],"name":"grantRole","outputs":[],"stateMutability":"nonpayable","type":"function"},
+    {"anonymous":false,"inputs":[{"indexed":true,"internalType":"bytes32","name":"pid","type":"bytes32"},{"indexed":true,"internalType":"address","name":"submitter","type":"address"},{"indexed":false,"internalType":"string","name":"description","type":"string"}],"name":"ProjectRegistered","type":"event"},
+    {"inputs":[{"internalType":"bytes32","name":"pid","type":"bytes32"},{"internalType":"string","name":"description","type":"string"},{"internalType":"uint256","name":"dataTTL","type":"uint256"}],"name":"registerProject","outputs":[],"stateMutability":"nonpayable","type":"function"},
+    {"inputs":[{"internalType":"bytes32","name":"role","type":"bytes32"},{"internalType":"address","name":"callerConfirmation","type":"address"}],"name":"renounceRole","outputs":[],"stateMutability":"nonpayable","type":"function"},
+    {"inputs":[{"internalType":"bytes32","name":"role","type":"bytes32"},{"internalType":"address","name":"account","type":"address"}],"name":"revokeRole","outputs":[],"stateMutability":"nonpayable","type":"function"},
+    {"anonymous":false,"inputs":[{"indexed":true,"internalType":"bytes32","name":"role","type":"bytes32"},{"indexed":true,"internalType":"bytes32","name":"previousAdminRole","type":"bytes32"},{"indexed":true,"internalType":"bytes32","name":"newAdminRole","type":"bytes32"}],"name":"RoleAdminChanged","type":"event"},
+    {"anonymous":false,"inputs":[{"indexed":true,"internalType":"bytes32","name":"role","type":"bytes32"},{"indexed":true,"internalType":"address","name":"account","type":"address"},{"indexed":true,"internalType":"address","name":"sender","type":"address"}],"name":"RoleGranted","type":"event"},
+    {"anonymous":false,"inputs":[{"indexed":true,"internalType":"bytes32","name":"role","type":"bytes32"},{"indexed":true,"internalType":"address","name":"account","type":"address"},{"indexed":true,"internalType":"address","name":"sender","type":"address"}],"name":"RoleRevoked","type":"event"},
+    {"inputs":[{"internalType":"bytes32","name":"pid","type":"bytes32"},{"internalType":"bytes32","name":"did","type":"bytes32"},{"internalType":"bytes","name":"coreData","type":"bytes"},{"internalType":"bytes32","name":"dataHash","type":"bytes32"}],"name":"submitData","outputs":[],"stateMutability":"nonpayable","type":"function"},
+    {"inputs":[{"internalType":"bytes32","name":"pid","type":"bytes32"},{"internalType":"uint256","name":"dataTTL","type":"uint256"}],"name":"updateProjectConfig","outputs":[],"stateMutability":"nonpayable","type":"function"},
+    {"inputs":[],"name":"DATA_UPLOADER_ROLE","outputs":[{"internalType":"bytes32","name":"","type":"bytes32"}],"stateMutability":"view","type":"function"},
+    {"inputs":[{"internalType":"bytes32","name":"did","type":"bytes32"}],"name":"decodeDidToYearMonthDay","outputs":[{"internalType":"uint16","name":"year","type":"uint16"},{"internalType":"uint8","name":"month","type":"uint8"},{"internalType":"uint8","name":"day","type":"uint8"}],"stateMutability":"pure","type":"function"},
+    {"inputs":[],"name":"DEFAULT_ADMIN_ROLE","outputs":[{"internalType":"bytes32","name":"","type":"bytes32"}],"stateMutability":"view","type":"function"},
+    {"inputs":[{"internalType":"uint16","name":"year","type":"uint16"},{"internalType":"uint8","name":"month","type":"uint8"},{"internalType":"uint8","name":"day","type":"uint8"}],"name":"encodeYearMonthDayToDid","outputs":[{"internalType":"bytes32","name":"did","type":"bytes32"}],"stateMutability":"pure","type":"function"},
+    {"inputs":[],"name":"getAllProjects","outputs":[{"internalType":"bytes32[]","name":"projects","type":"bytes32[]"}],"stateMutability":"view","type":"function"},
+    {"inputs":[{"internalType":"bytes32","name":"pid","type":"bytes32"},{"internalType":"bytes32","name":"did","type":"bytes32"}],"name":"getCoreData","outputs":[{"internalType":"bytes","name":"coreData","type":"bytes"}],"stateMutability":"view","type":"function"},
+    {"inputs":[{"internalType":"bytes32","name":"pid","type":"bytes32"},{"internalType":"bytes32","name":"did","type":"bytes32"}],"name":"getData","outputs":[{"internalType":"struct IOracle.OracleData","name":"data","type":"tuple","components":[{"internalType":"bytes32","name":"pid","type":"bytes32"},{"internalType":"bytes32","name":"did","type":"bytes32"},{"internalType":"bytes","name":"coreData","type":"bytes"},{"internalType":"bytes32","name":"dataHash","type":"bytes32"},{"internalType":"address","name":"submitter","type":"address"},{"internalType":"uint256","name":"submitTime","type":"uint256"}]}],"stateMutability":"view","type":"function"},
+    {"inputs":[{"internalType":"bytes32","name":"pid","type":"bytes32"},{"internalType":"bytes32","name":"did","type":"bytes32"}],"name":"getDataHash","outputs":[{"internalType":"bytes32","name":"dataHash","type":"bytes32"}],"stateMutability":"view","type":"function"},
+    {"inputs":[{"internalType":"bytes32","name":"pid","type":"bytes32"}],"name":"getDataIds","outputs":[{"internalType":"bytes32[]","name":"dids","type":"bytes32[]"}],"stateMutability":"view","type":"function"},
+    {"inputs":[{"internalType":"bytes32","name":"pid","type":"bytes32"},{"internalType":"bytes32","name":"prefix","type":"bytes32"}],"name":"getDataIdsByPrefix","outputs":[{"internalType":"bytes32[]","name":"matchingIds","type":"bytes32[]"}],"stateMutability":"view","type":"function"},
+    {"inputs":[{"internalType":"bytes32","name":"pid","type":"bytes32"},{"internalType":"uint16","name":"year","type":"uint16"},{"internalType":"uint8","name":"month","type":"uint8"}],"name":"getDataIdsByYearMonth","outputs":[{"internalType":"bytes32[]","name":"matchingIds","type":"bytes32[]"}],"stateMutability":"view","type":"function"},
+    {"inputs":[{"internalType":"bytes32","name":"pid","type":"bytes32"}],"name":"getLatestData","outputs":[{"internalType":"struct IOracle.OracleData","name":"data","type":"tuple","components":[{"internalType":"bytes32","name":"pid","type":"bytes32"},{"internalType":"bytes32","name":"did","type":"bytes32"},{"internalType":"bytes","name":"coreData","type":"bytes"},{"internalType":"bytes32","name":"dataHash","type":"bytes32"},{"internalType":"address","name":"submitter","type":"address"},{"internalType":"uint256","name":"submitTime","type":"uint256"}]}],"stateMutability":"view","type":"function"},
+    {"inputs":[{"internalType":"bytes32","name":"pid","type":"bytes32"},{"internalType":"uint16","name":"year","type":"uint16"},{"internalType":"uint8","name":"month","type":"uint8"}],"name":"getLatestDataByYearMonth","outputs":[{"internalType":"struct IOracle.OracleData","name":"data","type":"tuple","components":[{"internalType":"bytes32","name":"pid","type":"bytes32"},{"internalType":"bytes32","name":"did","type":"bytes32"},{"internalType":"bytes","name":"coreData","type":"bytes"},{"internalType":"bytes32","name":"dataHash","type":"bytes32"},{"internalType":"address","name":"submitter","type":"address"},{"internalType":"uint256","name":"submitTime","type":"uint256"}]}],"stateMutability":"view","type":"function"},
+    {"inputs":[{"internalType":"bytes32","name":"pid","type":"bytes32"}],"name":"getLatestDataId","outputs":[{"internalType":"bytes32","name":"did","type":"bytes32"}],"stateMutability":"view","type":"function"},
+    {"inputs":[{"internalType":"bytes32","name":"pid","type":"bytes32"}],"name":"getProjectConfig","outputs":[{"internalType":"struct IOracle.ProjectConfig","name":"config","type":"tuple","components":[{"internalType":"bool","name":"isActive","type":"bool"},{"internalType":"bytes","name":"description","type":"bytes"},{"internalType":"address[]","name":"authorizedSubmitters","type":"address[]"},{"internalType":"uint256","name":"dataTTL","type":"uint256"}]}],"stateMutability":"view","type":"function"},
+    {"inputs":[{"internalType":"address","name":"addr","type":"address"}],"name":"getProjectsByAddress","outputs":[{"internalType":"bytes32[]","name":"projects","type":"bytes32[]"}],"stateMutability":"view","type":"function"},
+    {"inputs":[{"internalType":"bytes32","name":"role","type":"bytes32"}],"name":"getRoleAdmin","outputs":[{"internalType":"bytes32","name":"","type":"bytes32"}],"stateMutability":"view","type":"function"},
+    {"inputs":[{"internalType":"bytes32","name":"role","type":"bytes32"},{"internalType":"address","name":"account","type":"address"}],"name":"hasRole","outputs":[{"internalType":"bool","name":"","type":"bool"}],"stateMutability":"view","type":"function"},
+    {"inputs":[{"internalType":"bytes32","name":"pid","type":"bytes32"},{"internalType":"address","name":"submitter","type":"address"}],"name":"isAuthorizedSubmitter","outputs":[{"internalType":"bool","name":"","type":"bool"}],"stateMutability":"view","type":"function"},
+    {"inputs":[{"internalType":"bytes32","name":"did","type":"bytes32"}],"name":"isValidYearMonthDayDid","outputs":[{"internalType":"bool","name":"isValid","type":"bool"}],"stateMutability":"pure","type":"function"},
+    {"inputs":[{"internalType":"bytes4","name":"interfaceId","type":"bytes4"}],"name":"supportsInterface","outputs":[{"internalType":"bool","name":"","type":"bool"}],"stateMutability":"view","type":"function"}
+
+]`
+
+// erc1271ABI 合约钱包（Safe等）的ERC-1271签名验证接口
+const erc1271ABI = `[
+    {"inputs":[{"internalType":"bytes32","name":"hash","type":"bytes32"},{"internalType":"bytes","name":"signature","type":"bytes"}],"name":"isValidSignature","outputs":[{"internalType":"bytes4","name":"magicValue","type":"bytes4"}],"stateMutability":"view","type":"function"}
+]`
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"oracle-backend/internal/config"
+	"oracle-backend/internal/models"
+	"oracle-backend/internal/store"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	// ErrAccessDenied 已登录地址无权下载该项目的文件
+	ErrAccessDenied = errors.New("access denied")
+	// ErrInvalidDownloadURL 签名下载链接无效或已过期
+	ErrInvalidDownloadURL = errors.New("invalid or expired download url")
+)
+
+// accessStore 项目下载权限存储，由main在启动时设置
+var accessStore store.ProjectAccessStore
+
+// accessConfig 下载访问控制配置，由main在启动时设置
+var accessConfig *config.AccessConfig
+
+// SetAccessStore 设置服务层使用的项目下载权限存储
+func SetAccessStore(as store.ProjectAccessStore) {
+	accessStore = as
+}
+
+// SetAccessConfig 设置服务层使用的下载访问控制配置
+func SetAccessConfig(cfg *config.AccessConfig) {
+	accessConfig = cfg
+}
+
+// GetProjectAccess 获取项目在指定链上的下载权限，未设置时返回默认配置
+func GetProjectAccess(chainId, projectId string) (*models.ProjectAccess, error) {
+	chain, err := ResolveChain(chainId)
+	if err != nil {
+		return nil, err
+	}
+	pid, err := ParseProjectID(projectId)
+	if err != nil {
+		return nil, err
+	}
+	return projectAccess(chain.ChainID, Bytes32ToHex(pid))
+}
+
+// UpdateProjectAccess 修改项目在指定链上的下载权限，address须为项目的授权提交者
+func UpdateProjectAccess(ctx context.Context, address, chainId, projectId string, req *models.UpdateProjectAccessRequest) (*models.ProjectAccess, error) {
+	if accessStore == nil {
+		return nil, errors.New("access store is not initialized")
+	}
+	if !req.Visibility.Valid() {
+		return nil, fmt.Errorf("invalid visibility: %q", req.Visibility)
+	}
+	chain, err := ResolveChain(chainId)
+	if err != nil {
+		return nil, err
+	}
+	pid, err := ParseProjectID(projectId)
+	if err != nil {
+		return nil, err
+	}
+
+	isAuthorized, err := CheckContractAuthorization(ctx, chain, address, Bytes32ToHex(pid))
+	if err != nil {
+		return nil, fmt.Errorf("合约权限检查失败: %w", err)
+	}
+	if !isAuthorized {
+		return nil, fmt.Errorf("%w: %s 不是项目 %s 的所有者或授权提交者", ErrAccessDenied, address, projectId)
+	}
+
+	a := models.ProjectAccess{
+		ChainID:    chain.ChainID,
+		Pid:        Bytes32ToHex(pid),
+		Visibility: req.Visibility,
+		UpdatedBy:  address,
+		UpdatedAt:  time.Now(),
+	}
+	// 允许列表只在allowlist可见性下生效，其他可见性不保存
+	if req.Visibility == models.VisibilityAllowlist {
+		for _, addr := range req.Allowlist {
+			if !common.IsHexAddress(addr) {
+				return nil, fmt.Errorf("invalid allowlist address: %q", addr)
+			}
+			if checksum := common.HexToAddress(addr).Hex(); !slices.Contains(a.Allowlist, checksum) {
+				a.Allowlist = append(a.Allowlist, checksum)
+			}
+		}
+	}
+	if err := accessStore.SaveProjectAccess(a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// CheckFileAccess 检查地址能否下载文件，address为空表示未登录
+// 公开项目的文件所有人可以下载；其他可见性下，链上getProjectConfig的authorizedSubmitters可以下载，
+// allowlist可见性下允许列表中的地址也可以下载。未登录访问非公开文件时返回ErrUnauthenticated，无权限时返回ErrAccessDenied
+func CheckFileAccess(ctx context.Context, loc *models.FileLocation, address string) (*models.ProjectAccess, error) {
+	a, err := projectAccess(loc.ChainID, loc.Pid)
+	if err != nil {
+		return nil, err
+	}
+	if a.Visibility == models.VisibilityPublic {
+		return a, nil
+	}
+	if address == "" {
+		return a, ErrUnauthenticated
+	}
+
+	if a.Visibility == models.VisibilityAllowlist && slices.ContainsFunc(a.Allowlist, func(s string) bool { return addressEqual(s, address) }) {
+		return a, nil
+	}
+
+	if chainRegistry == nil {
+		return a, errors.New("chain registry is not initialized")
+	}
+	chain, err := chainRegistry.Get(loc.ChainID)
+	if err != nil {
+		return a, err
+	}
+	pid, err := HexToBytes32(loc.Pid)
+	if err != nil {
+		return a, err
+	}
+	info, err := GetProject(ctx, chain, pid)
+	if errors.Is(err, ErrProjectNotFound) {
+		return a, ErrAccessDenied
+	}
+	if err != nil {
+		return a, fmt.Errorf("failed to get project config: %w", err)
+	}
+	if slices.ContainsFunc(info.AuthorizedSubmitters, func(s common.Address) bool { return addressEqual(s.Hex(), address) }) {
+		return a, nil
+	}
+	return a, ErrAccessDenied
+}
+
+// CreateDownloadURL 为有权下载文件的地址生成限时的签名下载链接，用于分享给未登录的使用者
+func CreateDownloadURL(ctx context.Context, address, chainId, projectId, fileHash string, ttlSeconds uint64) (*models.DownloadURL, error) {
+	if accessConfig == nil {
+		return nil, errors.New("access config is not initialized")
+	}
+	ttl := accessConfig.URLTTL
+	if ttlSeconds > 0 {
+		ttl = time.Duration(ttlSeconds) * time.Second
+		if ttl > accessConfig.URLMaxTTL {
+			return nil, fmt.Errorf("有效期不能超过 %s", accessConfig.URLMaxTTL)
+		}
+	}
+
+	loc, err := LookupProjectFile(chainId, projectId, fileHash)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := CheckFileAccess(ctx, loc, address); err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(ttl).Truncate(time.Second)
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	query := url.Values{
+		"expires": {expires},
+		"sig":     {downloadSignature(loc, expires)},
+	}
+	return &models.DownloadURL{
+		URL:       fileURL(loc.ChainID, loc.Pid, loc.FileHash) + "?" + query.Encode(),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// VerifyDownloadURL 检查签名下载链接的过期时间和签名，签名绑定链、项目和文件哈希
+func VerifyDownloadURL(loc *models.FileLocation, expires, sig string) error {
+	if accessConfig == nil {
+		return errors.New("access config is not initialized")
+	}
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() >= expiresAt {
+		return ErrInvalidDownloadURL
+	}
+	expected, err := hex.DecodeString(downloadSignature(loc, expires))
+	if err != nil {
+		return err
+	}
+	actual, err := hex.DecodeString(sig)
+	if err != nil || !hmac.Equal(actual, expected) {
+		return ErrInvalidDownloadURL
+	}
+	return nil
+}
+
+// projectAccess 获取项目的下载权限，未设置时返回默认配置
+func projectAccess(chainID uint64, pid string) (*models.ProjectAccess, error) {
+	if accessStore == nil || accessConfig == nil {
+		return nil, errors.New("access control is not initialized")
+	}
+	a, err := accessStore.ProjectAccess(chainID, pid)
+	if err != nil {
+		return nil, err
+	}
+	if a == nil {
+		a = &models.ProjectAccess{
+			ChainID:    chainID,
+			Pid:        pid,
+			Visibility: models.Visibility(accessConfig.DefaultVisibility),
+		}
+	}
+	return a, nil
+}
+
+// downloadSignature 计算签名下载链接的HMAC-SHA256（十六进制）
+func downloadSignature(loc *models.FileLocation, expires string) string {
+	mac := hmac.New(sha256.New, accessConfig.URLSecret)
+	fmt.Fprintf(mac, "%d/%s/%s\n%s", loc.ChainID, loc.Pid, loc.FileHash, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"oracle-backend/internal/config"
+	"oracle-backend/internal/models"
+	"oracle-backend/internal/store"
+)
+
+// apiKeyPrefix API密钥原文的前缀，密钥格式为 oak_<ID>_<secret>
+const apiKeyPrefix = "oak_"
+
+var (
+	// ErrAPIKeyNotFound API密钥不存在、已过期或不属于当前地址
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	// ErrInvalidAPIKey API密钥无效、已吊销或已过期
+	ErrInvalidAPIKey = errors.New("invalid api key")
+)
+
+// apiKeyStore API密钥存储，由main在启动时设置
+var apiKeyStore store.APIKeyStore
+
+// SetAPIKeyStore 设置服务层使用的API密钥存储
+func SetAPIKeyStore(ks store.APIKeyStore) {
+	apiKeyStore = ks
+}
+
+// CreateAPIKey 为已登录的地址创建限定链和项目的API密钥
+// 该地址必须是每个指定项目在每条指定链上的所有者或授权提交者，登录时的SIWE签名即创建者的身份证明
+// 返回的密钥原文只在此时出现一次，数据库中只保存其哈希
+func CreateAPIKey(ctx context.Context, address string, req *models.CreateAPIKeyRequest) (string, *models.APIKey, error) {
+	if apiKeyStore == nil {
+		return "", nil, errors.New("api key store is not initialized")
+	}
+	if chainRegistry == nil {
+		return "", nil, errors.New("chain registry is not initialized")
+	}
+	if len(req.ChainIDs) == 0 || len(req.ProjectIDs) == 0 {
+		return "", nil, errors.New("至少需要指定一条链和一个项目")
+	}
+
+	ttl := authConfig.APIKeyMaxTTL
+	if req.TTLSeconds > 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+		if ttl > authConfig.APIKeyMaxTTL {
+			return "", nil, fmt.Errorf("有效期不能超过 %s", authConfig.APIKeyMaxTTL)
+		}
+	}
+
+	var chains []*config.ChainConfig
+	for _, chainID := range req.ChainIDs {
+		chain, err := chainRegistry.Get(chainID)
+		if err != nil {
+			return "", nil, fmt.Errorf("不支持的链: %w", err)
+		}
+		if !slices.ContainsFunc(chains, func(c *config.ChainConfig) bool { return c.ChainID == chainID }) {
+			chains = append(chains, chain)
+		}
+	}
+	var pids []string
+	for _, projectId := range req.ProjectIDs {
+		pid, err := ParseProjectID(projectId)
+		if err != nil {
+			return "", nil, err
+		}
+		if hex := Bytes32ToHex(pid); !slices.Contains(pids, hex) {
+			pids = append(pids, hex)
+		}
+	}
+
+	// 创建者必须在密钥覆盖的每条链上都有每个项目的提交权限
+	for _, chain := range chains {
+		for _, pid := range pids {
+			isAuthorized, err := CheckContractAuthorization(ctx, chain, address, pid)
+			if err != nil {
+				return "", nil, fmt.Errorf("合约权限检查失败: %w", err)
+			}
+			if !isAuthorized {
+				return "", nil, fmt.Errorf("地址未授权: %s 不是链 %d 上项目 %s 的所有者或授权提交者", address, chain.ChainID, pid)
+			}
+		}
+	}
+
+	id, err := randomHex(8)
+	if err != nil {
+		return "", nil, err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return "", nil, err
+	}
+	key := apiKeyPrefix + id + "_" + secret
+
+	now := time.Now()
+	k := models.APIKey{
+		ID:        id,
+		Name:      req.Name,
+		KeyHash:   tokenHash(key),
+		Address:   address,
+		ChainIDs:  make([]uint64, 0, len(chains)),
+		Pids:      pids,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+	for _, chain := range chains {
+		k.ChainIDs = append(k.ChainIDs, chain.ChainID)
+	}
+	if err := apiKeyStore.SaveAPIKey(k); err != nil {
+		return "", nil, err
+	}
+	k.KeyHash = ""
+	return key, &k, nil
+}
+
+// ListAPIKeys 获取地址创建的所有未过期API密钥（包括已吊销的）
+func ListAPIKeys(address string) ([]models.APIKey, error) {
+	if apiKeyStore == nil {
+		return nil, errors.New("api key store is not initialized")
+	}
+	keys, err := apiKeyStore.APIKeysByAddress(address, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	for i := range keys {
+		keys[i].KeyHash = ""
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey 吊销地址创建的API密钥，密钥不存在或不属于该地址时返回ErrAPIKeyNotFound
+func RevokeAPIKey(address, id string) (*models.APIKey, error) {
+	if apiKeyStore == nil {
+		return nil, errors.New("api key store is not initialized")
+	}
+	now := time.Now()
+	k, err := apiKeyStore.APIKey(id, now)
+	if errors.Is(err, store.ErrAPIKeyNotFound) || (err == nil && !addressEqual(k.Address, address)) {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	k, err = apiKeyStore.RevokeAPIKey(id, now)
+	if err != nil {
+		return nil, err
+	}
+	k.KeyHash = ""
+	return k, nil
+}
+
+// authenticateAPIKey 验证API密钥可用于在指定链上为指定项目上传，返回密钥绑定的记录
+// 密钥必须未吊销、未过期且覆盖该链和项目，绑定的地址当前仍须是项目的授权提交者
+func authenticateAPIKey(ctx context.Context, key string, chain *config.ChainConfig, pid string) (*models.APIKey, error) {
+	if apiKeyStore == nil {
+		return nil, errors.New("api key store is not initialized")
+	}
+	id, _, ok := strings.Cut(strings.TrimPrefix(key, apiKeyPrefix), "_")
+	if !strings.HasPrefix(key, apiKeyPrefix) || !ok {
+		return nil, ErrInvalidAPIKey
+	}
+
+	k, err := apiKeyStore.APIKey(id, time.Now())
+	if errors.Is(err, store.ErrAPIKeyNotFound) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(k.KeyHash), []byte(tokenHash(key))) != 1 || k.RevokedAt != nil {
+		return nil, ErrInvalidAPIKey
+	}
+	if !slices.Contains(k.ChainIDs, chain.ChainID) {
+		return nil, fmt.Errorf("API密钥不能用于链 %d", chain.ChainID)
+	}
+	if !slices.Contains(k.Pids, pid) {
+		return nil, fmt.Errorf("API密钥不能用于项目 %s", pid)
+	}
+
+	// 授权可能在密钥创建后被撤销，每次上传都重新检查
+	isAuthorized, err := CheckContractAuthorization(ctx, chain, k.Address, pid)
+	if err != nil {
+		return nil, fmt.Errorf("合约权限检查失败: %w", err)
+	}
+	if !isAuthorized {
+		return nil, fmt.Errorf("地址未授权: %s 不再是项目 %s 的所有者或授权提交者", k.Address, pid)
+	}
+	return k, nil
+}
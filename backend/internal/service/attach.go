@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"oracle-backend/internal/models"
+	"oracle-backend/internal/storage"
+)
+
+// ErrFileNotFound 哈希索引中不存在指定的文件，或文件已不在存储后端中
+var ErrFileNotFound = errors.New("file not found")
+
+// sha256HexLength SHA-256哈希的十六进制长度
+const sha256HexLength = 64
+
+// ParseFileHash 解析完整的SHA-256文件哈希（64位十六进制，可带0x前缀），返回小写形式
+// 不接受哈希前缀，避免短前缀匹配到任意文件
+func ParseFileHash(fileHash string) (string, error) {
+	fileHash = normalizeHash(fileHash)
+	if len(fileHash) != sha256HexLength {
+		return "", fmt.Errorf("invalid file hash: expected %d hex characters", sha256HexLength)
+	}
+	if _, err := hex.DecodeString(fileHash); err != nil {
+		return "", fmt.Errorf("invalid file hash: %w", err)
+	}
+	return fileHash, nil
+}
+
+// fileURL 返回文件在指定链和项目下的下载路径
+func fileURL(chainID uint64, pid, fileHash string) string {
+	return fmt.Sprintf("/attach/%d/%s/%s", chainID, pid, fileHash)
+}
+
+// LookupFiles 按完整的文件哈希查找文件在所有链和项目中的索引条目
+// 同一内容可能上传到多个项目，调用方须逐个检查访问权限
+func LookupFiles(fileHash string) ([]*models.FileLocation, error) {
+	if uploadRepo == nil {
+		return nil, errors.New("upload repository is not initialized")
+	}
+	fileHash, err := ParseFileHash(fileHash)
+	if err != nil {
+		return nil, err
+	}
+
+	locs, err := uploadRepo.FilesByHash(fileHash)
+	if err != nil {
+		return nil, err
+	}
+	if len(locs) == 0 {
+		return nil, ErrFileNotFound
+	}
+	result := make([]*models.FileLocation, len(locs))
+	for i := range locs {
+		result[i] = &locs[i]
+	}
+	return result, nil
+}
+
+// LookupProjectFile 在指定链和项目内按完整的文件哈希查找文件的索引条目
+func LookupProjectFile(chainId, projectId, fileHash string) (*models.FileLocation, error) {
+	if uploadRepo == nil {
+		return nil, errors.New("upload repository is not initialized")
+	}
+	fileHash, err := ParseFileHash(fileHash)
+	if err != nil {
+		return nil, err
+	}
+	chain, err := ResolveChain(chainId)
+	if err != nil {
+		return nil, err
+	}
+	pid, err := ParseProjectID(projectId)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := uploadRepo.ProjectFileByHash(chain.ChainID, Bytes32ToHex(pid), fileHash)
+	if err != nil {
+		return nil, err
+	}
+	if loc == nil {
+		return nil, ErrFileNotFound
+	}
+	return loc, nil
+}
+
+// OpenFile 从存储后端读取索引条目对应的文件，调用方负责关闭返回的Reader
+func OpenFile(ctx context.Context, loc *models.FileLocation) (io.ReadCloser, *storage.BlobInfo, error) {
+	if blobStore == nil {
+		return nil, nil, errors.New("storage backend is not initialized")
+	}
+	r, info, err := blobStore.Get(ctx, loc.StoragePath)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, ErrFileNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if info.ContentType == "" {
+		info.ContentType = loc.ContentType
+	}
+	return r, info, nil
+}
+
+// BackfillHashIndex 将存储后端中已有但尚未建立索引的文件加入哈希索引
+// key结构为 <chainId>/<pid>/<fileHash>，也接受早期上传的 <chainId>/<projectId>/<fileHash><后缀>，
+// 不符合该结构的文件会被跳过；返回符合结构的文件数
+func BackfillHashIndex(ctx context.Context) (int, error) {
+	if uploadRepo == nil {
+		return 0, errors.New("upload repository is not initialized")
+	}
+	if blobStore == nil {
+		return 0, errors.New("storage backend is not initialized")
+	}
+	blobs, err := blobStore.List(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+
+	scanned := 0
+	for _, blob := range blobs {
+		parts := strings.Split(blob.Key, "/")
+		if len(parts) != 3 || len(parts[2]) < sha256HexLength {
+			continue
+		}
+
+		chainID, err := strconv.ParseUint(parts[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		pid, err := ParseProjectID(parts[1])
+		if err != nil {
+			continue
+		}
+		fileHash, err := ParseFileHash(parts[2][:sha256HexLength])
+		if err != nil {
+			continue
+		}
+
+		if err := uploadRepo.IndexFile(models.FileLocation{
+			ChainID:     chainID,
+			Pid:         Bytes32ToHex(pid),
+			FileHash:    fileHash,
+			FileName:    parts[2],
+			ContentType: blob.ContentType,
+			StoragePath: blob.Key,
+		}); err != nil {
+			return scanned, err
+		}
+		scanned++
+	}
+	return scanned, nil
+}
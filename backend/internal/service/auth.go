@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"time"
+
+	"oracle-backend/internal/config"
+	"oracle-backend/internal/models"
+	"oracle-backend/internal/store"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// authPruneInterval 清理过期登录nonce、会话和API密钥的间隔
+const authPruneInterval = 10 * time.Minute
+
+// ErrUnauthenticated 会话令牌缺失、无效或已过期
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// authStore 登录nonce和会话存储，由main在启动时设置
+var authStore store.AuthStore
+
+// authConfig 登录配置，由main在启动时设置
+var authConfig = config.DefaultAuthConfig()
+
+// SetAuthStore 设置服务层使用的登录存储
+func SetAuthStore(as store.AuthStore) {
+	authStore = as
+}
+
+// SetAuthConfig 设置服务层使用的登录配置
+func SetAuthConfig(cfg *config.AuthConfig) {
+	authConfig = cfg
+}
+
+// IssueAuthNonce 签发SIWE登录消息使用的一次性nonce（32位十六进制，满足EIP-4361对nonce的要求）
+func IssueAuthNonce() (*models.AuthNonce, error) {
+	if authStore == nil {
+		return nil, errors.New("auth store is not initialized")
+	}
+	nonce, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	n := &models.AuthNonce{
+		Nonce:     nonce,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(authConfig.NonceTTL),
+	}
+	if err := authStore.SaveAuthNonce(*n); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// SignIn 验证SIWE登录消息和签名，签发会话令牌
+// host: 请求的Host，未配置允许的域名时SIWE消息中的域名必须与之一致
+// 返回的令牌只在此时出现一次，数据库中只保存其哈希
+func SignIn(ctx context.Context, host, message, signature string) (string, *models.Session, error) {
+	if authStore == nil {
+		return "", nil, errors.New("auth store is not initialized")
+	}
+	msg, err := ParseSIWEMessage(message)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(authConfig.Domains) > 0 {
+		if !slices.Contains(authConfig.Domains, msg.Domain) {
+			return "", nil, fmt.Errorf("登录消息的域名 %s 不在允许的列表中", msg.Domain)
+		}
+	} else if msg.Domain != host {
+		return "", nil, fmt.Errorf("登录消息的域名 %s 与请求的域名 %s 不一致", msg.Domain, host)
+	}
+
+	chain, err := ResolveChain(strconv.FormatUint(msg.ChainID, 10))
+	if err != nil {
+		return "", nil, fmt.Errorf("不支持的链: %w", err)
+	}
+
+	now := time.Now()
+	if msg.IssuedAt.Sub(now) > signatureConfig.MaxClockSkew {
+		return "", nil, errors.New("登录消息的签发时间超前于服务器时间")
+	}
+	if now.Sub(msg.IssuedAt) > authConfig.NonceTTL {
+		return "", nil, errors.New("登录消息已过期")
+	}
+	if msg.ExpirationTime != nil && !now.Before(*msg.ExpirationTime) {
+		return "", nil, errors.New("登录消息已过期")
+	}
+	if msg.NotBefore != nil && msg.NotBefore.Sub(now) > signatureConfig.MaxClockSkew {
+		return "", nil, errors.New("登录消息尚未生效")
+	}
+
+	// 普通账户直接从签名中恢复地址；恢复的地址不一致时按合约钱包（ERC-1271）验证
+	if signer, err := VerifySignature(message, signature); err != nil || !addressEqual(signer, msg.Address) {
+		hash, err := personalSignHash(message)
+		if err != nil {
+			return "", nil, err
+		}
+		if _, err := verifySignerSignature(ctx, chain, msg.Address, hash, signature); err != nil {
+			return "", nil, fmt.Errorf("签名验证失败: %w", err)
+		}
+	}
+
+	if err := authStore.ConsumeAuthNonce(msg.Nonce, now); err != nil {
+		if errors.Is(err, store.ErrNonceInvalid) {
+			return "", nil, errors.New("nonce无效、已使用或已过期")
+		}
+		return "", nil, err
+	}
+
+	token, err := randomHex(32)
+	if err != nil {
+		return "", nil, err
+	}
+	sess := &models.Session{
+		TokenHash: tokenHash(token),
+		Address:   msg.Address,
+		ChainID:   msg.ChainID,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(authConfig.SessionTTL),
+	}
+	// 会话不能比登录消息声明的过期时间更长
+	if msg.ExpirationTime != nil && msg.ExpirationTime.Before(sess.ExpiresAt) {
+		sess.ExpiresAt = *msg.ExpirationTime
+	}
+	if err := authStore.SaveSession(*sess); err != nil {
+		return "", nil, err
+	}
+	return token, sess, nil
+}
+
+// LookupSession 根据会话令牌获取未过期的会话
+func LookupSession(token string) (*models.Session, error) {
+	if authStore == nil {
+		return nil, errors.New("auth store is not initialized")
+	}
+	if token == "" {
+		return nil, ErrUnauthenticated
+	}
+	sess, err := authStore.Session(tokenHash(token), time.Now())
+	if errors.Is(err, store.ErrSessionNotFound) {
+		return nil, ErrUnauthenticated
+	}
+	return sess, err
+}
+
+// SignOut 删除会话令牌对应的会话
+func SignOut(token string) error {
+	if authStore == nil {
+		return errors.New("auth store is not initialized")
+	}
+	return authStore.DeleteSession(tokenHash(token))
+}
+
+// StartAuthPruner 在后台定期删除过期的登录nonce、会话和API密钥
+func StartAuthPruner(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(authPruneInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if n, err := authStore.PruneAuthState(time.Now()); err != nil {
+				log.Printf("auth: failed to prune expired entries: %v", err)
+			} else if n > 0 {
+				log.Printf("auth: pruned %d expired entries", n)
+			}
+		}
+	}()
+}
+
+// authAddressKey 请求context中已登录地址的key
+type authAddressKey struct{}
+
+// WithAuthAddress 返回带有已登录地址的context
+func WithAuthAddress(ctx context.Context, address string) context.Context {
+	return context.WithValue(ctx, authAddressKey{}, address)
+}
+
+// AuthAddress 获取context中的已登录地址
+func AuthAddress(ctx context.Context) (string, bool) {
+	address, ok := ctx.Value(authAddressKey{}).(string)
+	return address, ok && address != ""
+}
+
+// tokenHash 计算会话令牌的SHA-256，数据库中不保存令牌原文
+func tokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomHex 生成n字节的随机数并编码为十六进制
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// addressEqual 比较两个地址（不区分大小写）
+func addressEqual(a, b string) bool {
+	return common.IsHexAddress(a) && common.IsHexAddress(b) && common.HexToAddress(a) == common.HexToAddress(b)
+}
@@ -7,68 +7,187 @@ import (
 	"fmt"
 	"math/big"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"oracle-backend/internal/config"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
-// 定义合约ABI（仅包含isAuthorizedSubmitter函数）
-const oracleABI = `[
-    {
-        "inputs": [
-            {"internalType": "bytes32", "name": "pid", "type": "bytes32"},
-            {"internalType": "address", "name": "submitter", "type": "address"}
-        ],
-        "name": "isAuthorizedSubmitter",
-        "outputs": [{"internalType": "bool", "name": "", "type": "bool"}],
-        "stateMutability": "view",
-        "type": "function"
-    }
-]`
-
 // OracleClient 封装Oracle智能合约的调用
+// 连接出错时会自动重连，配置了多个RPC地址时依次切换
 type OracleClient struct {
+	mu              sync.RWMutex
 	client          *ethclient.Client
+	rpcURLs         []string
+	endpoint        int
 	contractAddress common.Address
 	contractABI     abi.ABI
+	// walletABI 合约钱包的ERC-1271接口，用于验证合约钱包的签名
+	walletABI   abi.ABI
+	callTimeout time.Duration
+	// verifiedChainID 当前连接的节点已确认的链ID，重连后需重新确认
+	verifiedChainID uint64
+	// offlineDid 离线did实现已与合约的纯函数核对一致
+	offlineDid atomic.Bool
 }
 
 // NewOracleClient 创建OracleClient实例
 // rpcURL: 以太坊节点RPC地址
 // contractAddress: 智能合约地址
 func NewOracleClient(rpcURL, contractAddress string) (*OracleClient, error) {
-	client, err := ethclient.Dial(rpcURL)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to Ethereum node: %w", err)
-	}
+	return newOracleClient([]string{rpcURL}, contractAddress, config.DefaultCallTimeout)
+}
+
+// NewOracleClientForChain 根据链配置创建OracleClient实例
+func NewOracleClientForChain(chain *config.ChainConfig) (*OracleClient, error) {
+	return newOracleClient(chain.RPCURLs, chain.ContractAddress, chain.CallTimeout())
+}
 
-	address := common.HexToAddress(contractAddress)
+func newOracleClient(rpcURLs []string, contractAddress string, callTimeout time.Duration) (*OracleClient, error) {
+	if len(rpcURLs) == 0 {
+		return nil, errors.New("no rpc url configured")
+	}
 
 	parsedABI, err := abi.JSON(strings.NewReader(oracleABI))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse ABI: %w", err)
 	}
+	walletABI, err := abi.JSON(strings.NewReader(erc1271ABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ERC-1271 ABI: %w", err)
+	}
 
-	return &OracleClient{
-		client:          client,
-		contractAddress: address,
+	oc := &OracleClient{
+		rpcURLs:         rpcURLs,
+		contractAddress: common.HexToAddress(contractAddress),
 		contractABI:     parsedABI,
-	}, nil
+		walletABI:       walletABI,
+		callTimeout:     callTimeout,
+	}
+	if err := oc.redial(nil); err != nil {
+		return nil, err
+	}
+	return oc, nil
+}
+
+// redial 建立到RPC节点的连接，从当前节点开始依次尝试所有配置的地址
+// stale: 已失效的连接；仅当它仍是当前连接时才重连，避免并发调用重复重连
+func (oc *OracleClient) redial(stale *ethclient.Client) error {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+
+	if oc.client != stale {
+		return nil
+	}
+	if stale != nil {
+		stale.Close()
+		oc.client = nil
+		oc.endpoint = (oc.endpoint + 1) % len(oc.rpcURLs)
+	}
+
+	var lastErr error
+	for i := range oc.rpcURLs {
+		idx := (oc.endpoint + i) % len(oc.rpcURLs)
+		client, err := ethclient.Dial(oc.rpcURLs[idx])
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		oc.client = client
+		oc.endpoint = idx
+		oc.verifiedChainID = 0
+		return nil
+	}
+	return fmt.Errorf("failed to connect to Ethereum node: %w", lastErr)
 }
 
 // Close 关闭以太坊客户端连接
 func (oc *OracleClient) Close() {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+
 	if oc.client != nil {
 		oc.client.Close()
+		oc.client = nil
+	}
+}
+
+// withClient 使用当前连接执行fn，遇到连接类错误时重连并重试一次
+// ctx未设置截止时间时使用callTimeout作为超时
+func (oc *OracleClient) withClient(ctx context.Context, fn func(ctx context.Context, client *ethclient.Client) error) error {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, oc.callTimeout)
+		defer cancel()
+	}
+
+	for attempt := 0; ; attempt++ {
+		oc.mu.RLock()
+		client := oc.client
+		oc.mu.RUnlock()
+
+		if client == nil {
+			// 连接已关闭，重新建立连接
+			if err := oc.redial(nil); err != nil {
+				return err
+			}
+			continue
+		}
+
+		err := fn(ctx, client)
+		if err == nil || attempt > 0 || !isConnectionError(ctx, err) {
+			return err
+		}
+		if dialErr := oc.redial(client); dialErr != nil {
+			return fmt.Errorf("%w (reconnect failed: %v)", err, dialErr)
+		}
 	}
 }
 
+// isConnectionError 判断错误是否由连接问题引起（而不是节点或合约返回的错误）
+func isConnectionError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, ethereum.NotFound) {
+		return false
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		return false
+	}
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		// 限流和服务端错误时切换节点
+		return httpErr.StatusCode == 429 || httpErr.StatusCode >= 500
+	}
+	return true
+}
+
+// callContract 在最新区块上执行只读合约调用
+func (oc *OracleClient) callContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error) {
+	return oc.callContractAt(ctx, msg, nil)
+}
+
+// callContractAt 在指定区块的状态上执行只读合约调用，block为nil时使用最新区块
+func (oc *OracleClient) callContractAt(ctx context.Context, msg ethereum.CallMsg, block *big.Int) ([]byte, error) {
+	var result []byte
+	err := oc.withClient(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		var err error
+		result, err = client.CallContract(ctx, msg, block)
+		return err
+	})
+	return result, err
+}
+
 // IsAuthorizedSubmitter 检查提交者是否被授权提交数据
 // pid: 项目ID (bytes32)
 // submitter: 提交者地址
-func (oc *OracleClient) IsAuthorizedSubmitter(pid [32]byte, submitter common.Address) (bool, error) {
+func (oc *OracleClient) IsAuthorizedSubmitter(ctx context.Context, pid [32]byte, submitter common.Address) (bool, error) {
 	// 准备函数调用数据
 	callData, err := oc.contractABI.Pack("isAuthorizedSubmitter", pid, submitter)
 	if err != nil {
@@ -82,7 +201,7 @@ func (oc *OracleClient) IsAuthorizedSubmitter(pid [32]byte, submitter common.Add
 	}
 
 	// 执行调用
-	result, err := oc.client.CallContract(context.Background(), msg, nil)
+	result, err := oc.callContract(ctx, msg)
 	if err != nil {
 		return false, fmt.Errorf("failed to call contract: %w", err)
 	}
@@ -100,23 +219,23 @@ func (oc *OracleClient) IsAuthorizedSubmitter(pid [32]byte, submitter common.Add
 // IsAuthorizedSubmitterHex 检查提交者是否被授权提交数据（使用十六进制字符串参数）
 // pidHex: 项目ID的十六进制字符串 (0x前缀可选)
 // submitterHex: 提交者地址的十六进制字符串 (0x前缀可选)
-func (oc *OracleClient) IsAuthorizedSubmitterHex(pidHex, submitterHex string) (bool, error) {
+func (oc *OracleClient) IsAuthorizedSubmitterHex(ctx context.Context, pidHex, submitterHex string) (bool, error) {
 	pid, err := HexToBytes32(pidHex)
 	if err != nil {
 		return false, fmt.Errorf("invalid pid: %w", err)
 	}
 
 	submitter := common.HexToAddress(submitterHex)
-	return oc.IsAuthorizedSubmitter(pid, submitter)
+	return oc.IsAuthorizedSubmitter(ctx, pid, submitter)
 }
 
 // IsAuthorizedSubmitterString 检查提交者是否被授权提交数据（使用字符串参数）
 // pidStr: 项目ID字符串（将转换为bytes32）
 // submitterHex: 提交者地址的十六进制字符串 (0x前缀可选)
-func (oc *OracleClient) IsAuthorizedSubmitterString(pidStr, submitterHex string) (bool, error) {
+func (oc *OracleClient) IsAuthorizedSubmitterString(ctx context.Context, pidStr, submitterHex string) (bool, error) {
 	pid := StringToBytes32(pidStr)
 	submitter := common.HexToAddress(submitterHex)
-	return oc.IsAuthorizedSubmitter(pid, submitter)
+	return oc.IsAuthorizedSubmitter(ctx, pid, submitter)
 }
 
 // StringToBytes32 将字符串转换为bytes32（左对齐）
@@ -168,8 +287,13 @@ func Bytes32ToString(b [32]byte) string {
 }
 
 // IsContractAddress 检查地址是否为合约地址
-func (oc *OracleClient) IsContractAddress(address common.Address) (bool, error) {
-	code, err := oc.client.CodeAt(context.Background(), address, nil)
+func (oc *OracleClient) IsContractAddress(ctx context.Context, address common.Address) (bool, error) {
+	var code []byte
+	err := oc.withClient(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		var err error
+		code, err = client.CodeAt(ctx, address, nil)
+		return err
+	})
 	if err != nil {
 		return false, fmt.Errorf("failed to get code: %w", err)
 	}
@@ -177,39 +301,113 @@ func (oc *OracleClient) IsContractAddress(address common.Address) (bool, error)
 }
 
 // GetChainID 获取当前链的ID
-func (oc *OracleClient) GetChainID() (*big.Int, error) {
-	chainID, err := oc.client.ChainID(context.Background())
+func (oc *OracleClient) GetChainID(ctx context.Context) (*big.Int, error) {
+	var chainID *big.Int
+	err := oc.withClient(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		var err error
+		chainID, err = client.ChainID(ctx)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get chain ID: %w", err)
 	}
 	return chainID, nil
 }
 
+// CheckChainID 确认当前连接的节点所在的链与期望的链ID一致，避免RPC地址配置错误时在其他链上检查授权
+// 确认结果按连接缓存，切换节点后重新查询
+func (oc *OracleClient) CheckChainID(ctx context.Context, expected uint64) error {
+	oc.mu.RLock()
+	verified := oc.verifiedChainID
+	oc.mu.RUnlock()
+	if verified == expected {
+		return nil
+	}
+
+	var (
+		chainID *big.Int
+		current *ethclient.Client
+	)
+	err := oc.withClient(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		var err error
+		chainID, err = client.ChainID(ctx)
+		current = client
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get chain ID: %w", err)
+	}
+	if !chainID.IsUint64() || chainID.Uint64() != expected {
+		return fmt.Errorf("节点所在链 %s 与配置的链 %d 不一致", chainID, expected)
+	}
+
+	oc.mu.Lock()
+	if oc.client == current {
+		oc.verifiedChainID = expected
+	}
+	oc.mu.Unlock()
+	return nil
+}
+
 // GetLatestBlockNumber 获取最新区块号
-func (oc *OracleClient) GetLatestBlockNumber() (uint64, error) {
-	header, err := oc.client.HeaderByNumber(context.Background(), nil)
+func (oc *OracleClient) GetLatestBlockNumber(ctx context.Context) (uint64, error) {
+	var number uint64
+	err := oc.withClient(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		var err error
+		number, err = client.BlockNumber(ctx)
+		return err
+	})
 	if err != nil {
 		return 0, fmt.Errorf("failed to get latest block: %w", err)
 	}
-	return header.Number.Uint64(), nil
+	return number, nil
 }
 
-// CheckContractAuthorization 检查地址是否在合约中授权
-// submitterAddress: 提交者地址
-// projectID: 项目ID
-func CheckContractAuthorization(submitterAddress, projectID string) (bool, error) {
-	rpcURL := "https://bnb-testnet.g.alchemy.com/v2/zaBnV8q3wBWZp5lp-SQ5FFhfXsfSxcnj"
-	contractAddress := "0x09a0F5933f6F8129f748Da18842c3e11205a75Bf"
+// chainRegistry 链配置注册表，由main在启动时设置
+var chainRegistry *config.ChainRegistry
+
+// clientPool 按链ID复用的合约客户端连接池，由main在启动时设置
+var clientPool *ClientPool
+
+// SetChainRegistry 设置服务层使用的链配置注册表
+func SetChainRegistry(registry *config.ChainRegistry) {
+	chainRegistry = registry
+}
+
+// SetClientPool 设置服务层使用的合约客户端连接池
+func SetClientPool(pool *ClientPool) {
+	clientPool = pool
+}
 
-	if contractAddress == "0x..." {
-		return true, nil
+// ResolveChain 根据客户端声明的链ID获取链配置，未配置的链ID会被拒绝
+func ResolveChain(chainID string) (*config.ChainConfig, error) {
+	if chainRegistry == nil {
+		return nil, errors.New("chain registry is not initialized")
 	}
+	return chainRegistry.Resolve(chainID)
+}
 
-	client, err := NewOracleClient(rpcURL, contractAddress)
+// ChainClient 从连接池获取指定链的合约客户端
+func ChainClient(chain *config.ChainConfig) (*OracleClient, error) {
+	if clientPool == nil {
+		return nil, errors.New("client pool is not initialized")
+	}
+	return clientPool.Get(chain.ChainID)
+}
+
+// CheckContractAuthorization 检查地址是否在合约中授权
+// chain: 客户端声明的链对应的配置
+// submitterAddress: 提交者地址
+// projectID: 项目ID，按ParseProjectID解析（与上传记录使用的pid一致）
+func CheckContractAuthorization(ctx context.Context, chain *config.ChainConfig, submitterAddress, projectID string) (bool, error) {
+	pid, err := ParseProjectID(projectID)
+	if err != nil {
+		return false, err
+	}
+	client, err := ChainClient(chain)
 	if err != nil {
-		return false, fmt.Errorf("创建合约客户端失败: %w", err)
+		return false, fmt.Errorf("获取合约客户端失败: %w", err)
 	}
-	defer client.Close()
 
-	return client.IsAuthorizedSubmitterString(projectID, submitterAddress)
+	return client.IsAuthorizedSubmitter(ctx, pid, common.HexToAddress(submitterAddress))
 }
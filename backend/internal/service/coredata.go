@@ -0,0 +1,38 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"oracle-backend/pkg/coredata"
+)
+
+// verifyCoreData 解析表单中的核心数据，检查其keccak256与签名的coreDataHash一致并解码为键值对
+// 返回原始字节和以十进制字符串表示的键值对（uint256超出JSON数字精度）
+func verifyCoreData(coreDataStr, coreDataHash string) ([]byte, map[string]string, error) {
+	coreData, values, err := decodeCoreData(coreDataStr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := verifyCoreDataHash(coreData, coreDataHash); err != nil {
+		return nil, nil, err
+	}
+	return coreData, values, nil
+}
+
+// decodeCoreData 解析表单中的核心数据并按前端的序列化格式解码为键值对
+func decodeCoreData(coreDataStr string) ([]byte, map[string]string, error) {
+	if coreDataStr == "" {
+		return nil, nil, errors.New("缺少核心数据")
+	}
+	coreData, err := ParseCoreDataForm(coreDataStr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entries, err := coredata.Deserialize(coreData)
+	if err != nil {
+		return nil, nil, fmt.Errorf("核心数据解码失败: %w", err)
+	}
+	return coreData, entries.Strings(), nil
+}
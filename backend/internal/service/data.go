@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"oracle-backend/internal/config"
+	"oracle-backend/internal/models"
+	"oracle-backend/pkg/coredata"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// GetLatestRecord 获取项目最新的数据记录，哪条记录是最新的由合约判定，始终调用getLatestData
+// address为已登录地址（未登录为空），按项目的可见性决定是否返回关联的文件
+func GetLatestRecord(ctx context.Context, chain *config.ChainConfig, pid [32]byte, address string) (*models.DataRecordView, error) {
+	showFiles, err := filesVisible(ctx, chain, pid, address)
+	if err != nil {
+		return nil, err
+	}
+	client, err := ChainClient(chain)
+	if err != nil {
+		return nil, err
+	}
+	record, err := client.GetLatestData(ctx, pid)
+	if err != nil {
+		return nil, recordCallError(err)
+	}
+	return recordView(ctx, chain, client, record, showFiles)
+}
+
+// GetRecord 获取指定项目和数据ID的数据记录，索引已追上且包含该记录时不访问RPC节点
+func GetRecord(ctx context.Context, chain *config.ChainConfig, pid, did [32]byte, address string) (*models.DataRecordView, error) {
+	showFiles, err := filesVisible(ctx, chain, pid, address)
+	if err != nil {
+		return nil, err
+	}
+
+	if indexCaughtUp(chain) {
+		r, err := indexReader.IndexedRecord(chain.ChainID, Bytes32ToHex(pid), Bytes32ToHex(did))
+		if err != nil {
+			return nil, err
+		}
+		// 尚未确认的记录不在索引中，较早版本写入的条目没有记录内容，都改为调用合约
+		if r != nil && r.DataHash != "" {
+			return indexedRecordView(ctx, chain, r, showFiles)
+		}
+	}
+
+	client, err := ChainClient(chain)
+	if err != nil {
+		return nil, err
+	}
+	record, err := client.GetData(ctx, pid, did)
+	if err != nil {
+		return nil, recordCallError(err)
+	}
+	return recordView(ctx, chain, client, record, showFiles)
+}
+
+// ListRecordsByYearMonth 获取项目在指定年月内的所有数据记录（按合约返回的数据ID顺序）
+// 索引已追上时从DataSubmitted事件索引中按日期筛选（按数据ID排序），不访问RPC节点
+func ListRecordsByYearMonth(ctx context.Context, chain *config.ChainConfig, pid [32]byte, year uint16, month uint8, address string) ([]*models.DataRecordView, error) {
+	showFiles, err := filesVisible(ctx, chain, pid, address)
+	if err != nil {
+		return nil, err
+	}
+
+	if indexCaughtUp(chain) {
+		views, ok, err := listIndexedRecords(ctx, chain, pid, year, month, showFiles)
+		if err != nil || ok {
+			return views, err
+		}
+	}
+
+	client, err := ChainClient(chain)
+	if err != nil {
+		return nil, err
+	}
+	dids, err := client.GetDataIdsByYearMonth(ctx, pid, year, month)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]*models.DataRecordView, 0, len(dids))
+	for _, did := range dids {
+		var view *models.DataRecordView
+		record, err := client.GetData(ctx, pid, did)
+		if err != nil {
+			err = recordCallError(err)
+		} else {
+			view, err = recordView(ctx, chain, client, record, showFiles)
+		}
+		if errors.Is(err, ErrRecordNotFound) {
+			// 数据ID存在但记录已被删除或过期
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get record %s: %w", Bytes32ToHex(did), err)
+		}
+		views = append(views, view)
+	}
+	return views, nil
+}
+
+// recordView 将链上数据记录转换为HTTP响应：解码数据ID和核心数据，showFiles为true时关联本地保存的文件
+func recordView(ctx context.Context, chain *config.ChainConfig, client *OracleClient, record *models.OracleRecord, showFiles bool) (*models.DataRecordView, error) {
+	if record.SubmitTime == nil || record.SubmitTime.Sign() == 0 {
+		return nil, ErrRecordNotFound
+	}
+
+	year, month, day, err := client.DecodeDid(ctx, record.Did)
+	if err != nil {
+		return nil, fmt.Errorf("解码数据ID失败: %w", err)
+	}
+
+	view := &models.DataRecordView{
+		ChainID:    chain.ChainID,
+		Pid:        record.Pid.Hex(),
+		Did:        record.Did.Hex(),
+		Year:       year,
+		Month:      month,
+		Day:        day,
+		CoreData:   record.CoreData,
+		DataHash:   record.DataHash.Hex(),
+		Submitter:  record.Submitter.Hex(),
+		SubmitTime: record.SubmitTime.Uint64(),
+	}
+	return view, completeRecordView(chain, view, showFiles)
+}
+
+// completeRecordView 解码记录的核心数据，showFiles为true时关联本地保存的文件
+func completeRecordView(chain *config.ChainConfig, view *models.DataRecordView, showFiles bool) error {
+	view.Files = []models.AttachedFile{}
+	view.FilesHidden = !showFiles
+	if entries, err := coredata.Deserialize(view.CoreData); err != nil {
+		view.CoreDataError = err.Error()
+	} else {
+		view.CoreDataValues = entries.Strings()
+	}
+
+	if showFiles && uploadRepo != nil {
+		uploads, err := uploadRepo.UploadsBySubmission(chain.ChainID, view.Pid, view.Did)
+		if err != nil {
+			return err
+		}
+		for _, u := range uploads {
+			view.Files = append(view.Files, models.AttachedFile{
+				FileName:    u.FileName,
+				FileHash:    u.FileHash,
+				FileSize:    u.FileSize,
+				ContentType: u.ContentType,
+				URL:         fileURL(chain.ChainID, view.Pid, u.FileHash),
+			})
+		}
+	}
+	return nil
+}
+
+// listIndexedRecords 从索引中获取项目在指定年月内的数据记录，索引条目缺少日期或记录内容时返回false，由调用方改为调用合约
+func listIndexedRecords(ctx context.Context, chain *config.ChainConfig, pid [32]byte, year uint16, month uint8, showFiles bool) ([]*models.DataRecordView, bool, error) {
+	records, err := indexReader.IndexedRecords(chain.ChainID, Bytes32ToHex(pid))
+	if err != nil {
+		return nil, false, err
+	}
+	for _, r := range records {
+		if r.Year == 0 || r.DataHash == "" {
+			return nil, false, nil
+		}
+	}
+
+	views := make([]*models.DataRecordView, 0)
+	for i := range records {
+		if records[i].Year != year || records[i].Month != month {
+			continue
+		}
+		view, err := indexedRecordView(ctx, chain, &records[i], showFiles)
+		if errors.Is(err, ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get record %s: %w", records[i].Did, err)
+		}
+		views = append(views, view)
+	}
+	return views, true, nil
+}
+
+// indexedRecordView 将索引中的数据记录转换为HTTP响应
+// 超过项目dataTTL的记录合约不再返回，索引中的记录同样视为不存在（项目配置按链缓存）
+func indexedRecordView(ctx context.Context, chain *config.ChainConfig, r *models.IndexedDataRecord, showFiles bool) (*models.DataRecordView, error) {
+	pid, err := HexToBytes32(r.Pid)
+	if err != nil {
+		return nil, err
+	}
+	project, err := GetProject(ctx, chain, pid)
+	if err != nil {
+		return nil, fmt.Errorf("获取项目配置失败: %w", err)
+	}
+	if ttl := project.DataTTL; ttl != nil && ttl.Sign() > 0 && ttl.IsUint64() && r.Timestamp+ttl.Uint64() <= uint64(time.Now().Unix()) {
+		return nil, ErrRecordNotFound
+	}
+
+	coreData, err := hexutil.Decode(r.CoreData)
+	if err != nil {
+		return nil, fmt.Errorf("索引中的核心数据无效: %w", err)
+	}
+	view := &models.DataRecordView{
+		ChainID:    chain.ChainID,
+		Pid:        r.Pid,
+		Did:        r.Did,
+		Year:       r.Year,
+		Month:      r.Month,
+		Day:        r.Day,
+		CoreData:   coreData,
+		DataHash:   r.DataHash,
+		Submitter:  r.Submitter,
+		SubmitTime: r.Timestamp,
+	}
+	return view, completeRecordView(chain, view, showFiles)
+}
+
+// filesVisible 按项目的可见性判断地址（未登录为空）能否查看数据记录关联的文件，与下载文件时的检查相同
+func filesVisible(ctx context.Context, chain *config.ChainConfig, pid [32]byte, address string) (bool, error) {
+	_, err := CheckFileAccess(ctx, &models.FileLocation{ChainID: chain.ChainID, Pid: Bytes32ToHex(pid)}, address)
+	if errors.Is(err, ErrUnauthenticated) || errors.Is(err, ErrAccessDenied) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// LoadIndexedRecord 在索引DataSubmitted事件时读取记录的核心数据和dataHash并解码数据ID，写入r
+// 核心数据和dataHash按事件所在区块（r.BlockNumber）的状态读取，追赶历史区块时不会取到之后重新提交的内容，节点须保留这些区块的状态
+// 合约回滚（记录已过期或数据ID无效）时只保留事件信息，读取接口对这类条目改为调用合约；其他错误返回给索引器重试
+func LoadIndexedRecord(ctx context.Context, client *OracleClient, r *models.IndexedDataRecord) error {
+	pid, err := HexToBytes32(r.Pid)
+	if err != nil {
+		return err
+	}
+	did, err := HexToBytes32(r.Did)
+	if err != nil {
+		return err
+	}
+
+	year, month, day, err := client.DecodeDid(ctx, did)
+	if err != nil {
+		if errors.Is(recordCallError(err), ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	record, err := client.GetDataAt(ctx, pid, did, r.BlockNumber)
+	if err != nil {
+		if errors.Is(recordCallError(err), ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	r.Year, r.Month, r.Day = year, month, day
+	r.CoreData = hexutil.Encode(record.CoreData)
+	r.DataHash = record.DataHash.Hex()
+	if record.SubmitTime != nil && record.SubmitTime.IsUint64() {
+		r.Timestamp = record.SubmitTime.Uint64()
+	}
+	return nil
+}
+
+// recordCallError 合约在记录不存在时回滚，转换为ErrRecordNotFound
+func recordCallError(err error) error {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) || strings.Contains(err.Error(), "execution reverted") {
+		return fmt.Errorf("%w: %v", ErrRecordNotFound, err)
+	}
+	return err
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"oracle-backend/pkg/dataid"
+)
+
+// didSamples 核对离线did实现时使用的有效日期（含闰年和月末边界）
+var didSamples = []struct {
+	year       uint16
+	month, day uint8
+}{
+	{2024, 2, 29}, {2025, 1, 1}, {2025, 2, 28}, {2025, 12, 31}, {2000, 2, 29}, {2099, 4, 30},
+}
+
+// invalidDidSamples 核对时使用的无效数据ID：不存在的日期、非数字字符和全零
+var invalidDidSamples = []string{"20250229", "19000229", "20251301", "20250400", "20250431", "2025-1-1", ""}
+
+// VerifyDidEncoding 用样本日期比较离线did实现与合约的纯函数，全部一致时该客户端改用离线实现，
+// 之后编码和解码did不再访问RPC节点；不一致或核对失败时继续调用合约
+func (oc *OracleClient) VerifyDidEncoding(ctx context.Context) error {
+	for _, s := range didSamples {
+		expected, err := oc.EncodeYearMonthDayToDid(ctx, s.year, s.month, s.day)
+		if err != nil {
+			return err
+		}
+		actual, err := dataid.Encode(s.year, s.month, s.day)
+		if err != nil {
+			return err
+		}
+		if actual != expected {
+			return fmt.Errorf("encode %04d-%02d-%02d: contract returned %s, offline %s",
+				s.year, s.month, s.day, Bytes32ToHex(expected), Bytes32ToHex(actual))
+		}
+
+		year, month, day, err := oc.DecodeDidToYearMonthDay(ctx, expected)
+		if err != nil {
+			return err
+		}
+		if year != s.year || month != s.month || day != s.day {
+			return fmt.Errorf("decode %s: contract returned %04d-%02d-%02d", Bytes32ToHex(expected), year, month, day)
+		}
+		if err := oc.compareDidValidity(ctx, expected); err != nil {
+			return err
+		}
+	}
+
+	for _, s := range invalidDidSamples {
+		if err := oc.compareDidValidity(ctx, StringToBytes32(s)); err != nil {
+			return err
+		}
+	}
+
+	oc.offlineDid.Store(true)
+	return nil
+}
+
+// compareDidValidity 比较合约与离线实现对数据ID有效性的判断
+func (oc *OracleClient) compareDidValidity(ctx context.Context, did [32]byte) error {
+	valid, err := oc.IsValidYearMonthDayDid(ctx, did)
+	if err != nil {
+		return err
+	}
+	if valid != dataid.IsValid(did) {
+		return fmt.Errorf("validity of %s: contract returned %v, offline %v", Bytes32ToHex(did), valid, !valid)
+	}
+	return nil
+}
+
+// EncodeDid 将日期编码为数据ID，离线实现已通过核对时不访问RPC节点
+func (oc *OracleClient) EncodeDid(ctx context.Context, year uint16, month, day uint8) ([32]byte, error) {
+	if oc.offlineDid.Load() {
+		return dataid.Encode(year, month, day)
+	}
+	return oc.EncodeYearMonthDayToDid(ctx, year, month, day)
+}
+
+// DecodeDid 将数据ID解码为日期，离线实现已通过核对时不访问RPC节点
+func (oc *OracleClient) DecodeDid(ctx context.Context, did [32]byte) (uint16, uint8, uint8, error) {
+	if oc.offlineDid.Load() {
+		return dataid.Decode(did)
+	}
+	return oc.DecodeDidToYearMonthDay(ctx, did)
+}
+
+// VerifyDidEncodings 逐链核对离线did实现，核对失败的链继续调用合约的纯函数
+func VerifyDidEncodings(ctx context.Context) {
+	if chainRegistry == nil || clientPool == nil {
+		return
+	}
+	for _, chainID := range chainRegistry.ChainIDs() {
+		client, err := clientPool.Get(chainID)
+		if err == nil {
+			err = client.VerifyDidEncoding(ctx)
+		}
+		if err != nil {
+			log.Printf("Warning: chain %d: offline did encoding not verified, using contract calls: %v", chainID, err)
+			continue
+		}
+		log.Printf("Chain %d: offline did encoding verified against contract", chainID)
+	}
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"oracle-backend/pkg/dataid"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// fakeDidContract 只实现合约did纯函数的eth_call，默认与离线实现一致，各字段可替换为不一致的实现
+type fakeDidContract struct {
+	abi     abi.ABI
+	encode  func(year uint16, month, day uint8) ([32]byte, error)
+	decode  func(did [32]byte) (uint16, uint8, uint8, error)
+	isValid func(did [32]byte) bool
+	calls   atomic.Int32
+}
+
+type fakeCallArgs struct {
+	Data  *hexutil.Bytes `json:"data"`
+	Input *hexutil.Bytes `json:"input"`
+}
+
+func (f *fakeDidContract) Call(args fakeCallArgs, _ interface{}, _ *interface{}) (hexutil.Bytes, error) {
+	f.calls.Add(1)
+	data := args.Input
+	if data == nil {
+		data = args.Data
+	}
+	if data == nil || len(*data) < 4 {
+		return nil, errors.New("missing call data")
+	}
+	method, err := f.abi.MethodById((*data)[:4])
+	if err != nil {
+		return nil, err
+	}
+	in, err := method.Inputs.Unpack((*data)[4:])
+	if err != nil {
+		return nil, err
+	}
+
+	switch method.Name {
+	case "encodeYearMonthDayToDid":
+		did, err := f.encode(in[0].(uint16), in[1].(uint8), in[2].(uint8))
+		if err != nil {
+			return nil, err
+		}
+		return method.Outputs.Pack(did)
+	case "decodeDidToYearMonthDay":
+		year, month, day, err := f.decode(in[0].([32]byte))
+		if err != nil {
+			return nil, err
+		}
+		return method.Outputs.Pack(year, month, day)
+	case "isValidYearMonthDayDid":
+		return method.Outputs.Pack(f.isValid(in[0].([32]byte)))
+	}
+	return nil, errors.New("unexpected call to " + method.Name)
+}
+
+func newFakeDidClient(t *testing.T, mutate func(f *fakeDidContract)) (*OracleClient, *fakeDidContract) {
+	t.Helper()
+	parsed, err := abi.JSON(strings.NewReader(oracleABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeDidContract{abi: parsed, encode: dataid.Encode, decode: dataid.Decode, isValid: dataid.IsValid}
+	if mutate != nil {
+		mutate(f)
+	}
+
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("eth", f); err != nil {
+		t.Fatal(err)
+	}
+	hs := httptest.NewServer(srv)
+	t.Cleanup(hs.Close)
+
+	client, err := NewOracleClient(hs.URL, "0x00000000000000000000000000000000000000c0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+	return client, f
+}
+
+func TestVerifyDidEncodingMatching(t *testing.T) {
+	client, f := newFakeDidClient(t, nil)
+	ctx := context.Background()
+
+	if err := client.VerifyDidEncoding(ctx); err != nil {
+		t.Fatalf("VerifyDidEncoding: %v", err)
+	}
+	if !client.offlineDid.Load() {
+		t.Fatal("offline did encoding not enabled after a successful check")
+	}
+
+	before := f.calls.Load()
+	did, err := client.EncodeDid(ctx, 2024, 2, 29)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := client.DecodeDid(ctx, did); err != nil {
+		t.Fatal(err)
+	}
+	if f.calls.Load() != before {
+		t.Fatal("EncodeDid/DecodeDid called the contract after the offline path was enabled")
+	}
+}
+
+func TestVerifyDidEncodingMismatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(f *fakeDidContract)
+	}{
+		{name: "right aligned encoding", mutate: func(f *fakeDidContract) {
+			f.encode = func(year uint16, month, day uint8) ([32]byte, error) {
+				left, err := dataid.Encode(year, month, day)
+				var did [32]byte
+				copy(did[24:], left[:8])
+				return did, err
+			}
+			f.decode = func(did [32]byte) (uint16, uint8, uint8, error) {
+				var left [32]byte
+				copy(left[:], did[24:])
+				return dataid.Decode(left)
+			}
+			f.isValid = func(did [32]byte) bool { return false }
+		}},
+		{name: "decode swaps month and day", mutate: func(f *fakeDidContract) {
+			f.decode = func(did [32]byte) (uint16, uint8, uint8, error) {
+				year, month, day, err := dataid.Decode(did)
+				return year, day, month, err
+			}
+		}},
+		{name: "no leap year rule", mutate: func(f *fakeDidContract) {
+			f.isValid = func(did [32]byte) bool {
+				return dataid.IsValid(did) || string(did[4:8]) == "0229"
+			}
+		}},
+		{name: "rejects month end", mutate: func(f *fakeDidContract) {
+			f.isValid = func(did [32]byte) bool {
+				return dataid.IsValid(did) && string(did[6:8]) != "31"
+			}
+		}},
+		{name: "contract call fails", mutate: func(f *fakeDidContract) {
+			f.encode = func(uint16, uint8, uint8) ([32]byte, error) {
+				return [32]byte{}, errors.New("execution reverted")
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, f := newFakeDidClient(t, tt.mutate)
+			ctx := context.Background()
+
+			if err := client.VerifyDidEncoding(ctx); err == nil {
+				t.Fatal("VerifyDidEncoding succeeded against a mismatching contract")
+			}
+			if client.offlineDid.Load() {
+				t.Fatal("offline did encoding enabled after a mismatch")
+			}
+
+			// 核对失败后继续调用合约
+			before := f.calls.Load()
+			client.EncodeDid(ctx, 2025, 1, 1)
+			if f.calls.Load() == before {
+				t.Fatal("EncodeDid did not call the contract after a mismatch")
+			}
+		})
+	}
+}
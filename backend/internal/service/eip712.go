@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"oracle-backend/internal/config"
+	"oracle-backend/internal/models"
+
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// OracleUpload签名的EIP-712域，chainId和verifyingContract取自链配置
+const (
+	EIP712DomainName    = "Oracle"
+	EIP712DomainVersion = "1"
+	// oracleUploadType 上传授权的结构化数据类型名
+	oracleUploadType = "OracleUpload"
+)
+
+// oracleUploadTypes OracleUpload的EIP-712类型定义，前端signTypedData必须使用相同的定义
+var oracleUploadTypes = apitypes.Types{
+	"EIP712Domain": {
+		{Name: "name", Type: "string"},
+		{Name: "version", Type: "string"},
+		{Name: "chainId", Type: "uint256"},
+		{Name: "verifyingContract", Type: "address"},
+	},
+	oracleUploadType: {
+		{Name: "projectId", Type: "string"},
+		{Name: "dataDate", Type: "string"},
+		{Name: "coreDataHash", Type: "bytes32"},
+		{Name: "fileHashes", Type: "bytes32[]"},
+		{Name: "chainId", Type: "uint256"},
+		{Name: "timestamp", Type: "uint256"},
+		{Name: "nonce", Type: "uint256"},
+	},
+}
+
+// OracleUploadTypedData 按签名数据构造链上对应的OracleUpload结构化数据
+func OracleUploadTypedData(chain *config.ChainConfig, sigData *models.SignatureData) (apitypes.TypedData, error) {
+	if sigData.Timestamp < 0 {
+		return apitypes.TypedData{}, fmt.Errorf("invalid timestamp: %d", sigData.Timestamp)
+	}
+	nonce, ok := new(big.Int).SetString(sigData.Nonce, 10)
+	if !ok || nonce.Sign() < 0 {
+		return apitypes.TypedData{}, fmt.Errorf("invalid nonce: %q", sigData.Nonce)
+	}
+	coreDataHash, err := HexToBytes32(sigData.CoreDataHash)
+	if err != nil {
+		return apitypes.TypedData{}, fmt.Errorf("invalid core data hash: %w", err)
+	}
+
+	// 文件哈希为SHA-256，按bytes32参与签名
+	fileHashes := make([]interface{}, len(sigData.FileHashes))
+	for i, h := range sigData.FileHashes {
+		fileHash, err := ParseFileHash(h)
+		if err != nil {
+			return apitypes.TypedData{}, err
+		}
+		fileHashes[i] = "0x" + fileHash
+	}
+
+	return apitypes.TypedData{
+		Types:       oracleUploadTypes,
+		PrimaryType: oracleUploadType,
+		Domain: apitypes.TypedDataDomain{
+			Name:              EIP712DomainName,
+			Version:           EIP712DomainVersion,
+			ChainId:           math.NewHexOrDecimal256(int64(chain.ChainID)),
+			VerifyingContract: chain.Address().Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"projectId":    sigData.ProjectID,
+			"dataDate":     sigData.DataDate,
+			"coreDataHash": coreDataHash[:],
+			"fileHashes":   fileHashes,
+			"chainId":      new(big.Int).SetUint64(sigData.ChainID),
+			"timestamp":    big.NewInt(sigData.Timestamp),
+			"nonce":        nonce,
+		},
+	}, nil
+}
+
+// oracleUploadHash 计算OracleUpload结构化数据的EIP-712签名摘要
+func oracleUploadHash(chain *config.ChainConfig, sigData *models.SignatureData) ([]byte, error) {
+	typedData, err := OracleUploadTypedData(chain, sigData)
+	if err != nil {
+		return nil, err
+	}
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash typed data: %w", err)
+	}
+	return hash, nil
+}
+
+// signatureConfig 签名验证配置，由main在启动时设置
+var signatureConfig = config.DefaultSignatureConfig()
+
+// SetSignatureConfig 设置服务层使用的签名验证配置
+func SetSignatureConfig(cfg *config.SignatureConfig) {
+	signatureConfig = cfg
+}
+
+// checkSignatureType 检查上传的签名方式，signatureType省略时为EIP-712
+// personal_sign只在配置启用时接受；无论哪种方式，签名数据都必须包含chainId和服务端签发的nonce，签名才能绑定到链并防止重放
+func checkSignatureType(signatureType string, sigData *models.SignatureData) error {
+	switch signatureType {
+	case "", models.SignatureTypeEIP712:
+		if signatureType == "" && sigData.ChainID == 0 && sigData.Nonce == "" {
+			return errors.New("不再支持不含chainId和nonce的旧版签名，请刷新页面后使用EIP-712签名重新提交")
+		}
+	case models.SignatureTypePersonalSign:
+		if !signatureConfig.AllowPersonalSign {
+			return errors.New("服务端未启用personal_sign签名，请使用EIP-712签名")
+		}
+		if sigData.ChainID == 0 || sigData.Nonce == "" {
+			return errors.New("personal_sign签名数据必须包含chainId和nonce，请刷新页面后重新签名")
+		}
+	default:
+		return fmt.Errorf("不支持的签名方式: %s", signatureType)
+	}
+	return nil
+}
+
+// verifyUploadSignature 按签名方式验证上传签名，返回签名者地址和被签名的摘要
+// 调用前须已通过checkSignatureType和checkSignatureChain确认签名方式可用、签名数据中的链ID与提交的链一致
+// signerAddress为空时从ECDSA签名中恢复地址；非空时按声明的签名者验证，支持ERC-1271合约钱包
+func verifyUploadSignature(ctx context.Context, chain *config.ChainConfig, signatureType, signatureDataStr string,
+	sigData *models.SignatureData, signature, signerAddress string) (string, []byte, error) {
+	var (
+		hash []byte
+		err  error
+	)
+	if signatureType == models.SignatureTypePersonalSign {
+		// 直接验证前端签名的原始字符串，不依赖字段顺序和转义方式
+		hash, err = personalSignHash(signatureDataStr)
+	} else {
+		hash, err = oracleUploadHash(chain, sigData)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	var signer string
+	if signerAddress != "" {
+		signer, err = verifySignerSignature(ctx, chain, signerAddress, hash, signature)
+	} else {
+		signer, err = recoverSigner(hash, signature)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return signer, hash, nil
+}
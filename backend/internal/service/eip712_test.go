@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"oracle-backend/internal/config"
+	"oracle-backend/internal/models"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestPersonalSignSwitch(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := crypto.PubkeyToAddress(key.PublicKey).Hex()
+
+	legacy := models.SignatureData{
+		ProjectID:    "demo",
+		DataDate:     "2024-02-29",
+		CoreDataHash: "0x" + strings.Repeat("11", 32),
+		FileHashes:   []string{strings.Repeat("22", 32)},
+		ChainID:      97,
+		Timestamp:    1700000000000,
+		Nonce:        "12345",
+	}
+	raw, err := json.Marshal(legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash, err := personalSignHash(string(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := crypto.Sign(hash, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := hexutil.Encode(sig)
+
+	saved := signatureConfig
+	t.Cleanup(func() { signatureConfig = saved })
+
+	signatureConfig = config.DefaultSignatureConfig()
+	if err := checkSignatureType(models.SignatureTypePersonalSign, &legacy); err == nil {
+		t.Fatal("personal_sign accepted while the switch is off")
+	}
+
+	signatureConfig = &config.SignatureConfig{AllowPersonalSign: true}
+	if err := checkSignatureType(models.SignatureTypePersonalSign, &legacy); err != nil {
+		t.Fatalf("personal_sign rejected while the switch is on: %v", err)
+	}
+	got, gotHash, err := verifyUploadSignature(context.Background(), &config.ChainConfig{ChainID: 97},
+		models.SignatureTypePersonalSign, string(raw), &legacy, signature, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != signer {
+		t.Fatalf("recovered %s, want %s", got, signer)
+	}
+	if hexutil.Encode(gotHash) != hexutil.Encode(hash) {
+		t.Fatal("personal_sign must be verified over the raw signature data string")
+	}
+
+	// 启用后签名数据仍须包含chainId和nonce
+	for _, mutate := range []func(d *models.SignatureData){
+		func(d *models.SignatureData) { d.ChainID = 0 },
+		func(d *models.SignatureData) { d.Nonce = "" },
+	} {
+		data := legacy
+		mutate(&data)
+		if err := checkSignatureType(models.SignatureTypePersonalSign, &data); err == nil {
+			t.Fatalf("personal_sign accepted without chainId or nonce: %+v", data)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"oracle-backend/internal/config"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// erc1271MagicValue isValidSignature验证通过时返回的值：bytes4(keccak256("isValidSignature(bytes32,bytes)"))
+var erc1271MagicValue = [4]byte{0x16, 0x26, 0xba, 0x7e}
+
+// IsValidSignature 调用合约钱包的ERC-1271 isValidSignature，检查签名对哈希是否有效
+// 合约未实现该接口或调用回滚时返回false
+func (oc *OracleClient) IsValidSignature(ctx context.Context, wallet common.Address, hash [32]byte, signature []byte) (bool, error) {
+	callData, err := oc.walletABI.Pack("isValidSignature", hash, signature)
+	if err != nil {
+		return false, fmt.Errorf("failed to pack call data: %w", err)
+	}
+
+	result, err := oc.callContract(ctx, ethereum.CallMsg{
+		To:   &wallet,
+		Data: callData,
+	})
+	if err != nil {
+		if isConnectionError(ctx, err) || ctx.Err() != nil {
+			return false, fmt.Errorf("failed to call isValidSignature: %w", err)
+		}
+		// 调用回滚，视为签名无效
+		return false, nil
+	}
+	// 返回值为左对齐的bytes4
+	return len(result) >= 4 && bytes.Equal(result[:4], erc1271MagicValue[:]), nil
+}
+
+// verifySignerSignature 按声明的签名者地址验证签名
+// 签名者是合约（如Safe）时调用其ERC-1271 isValidSignature，否则恢复ECDSA签名并与声明的地址比较
+func verifySignerSignature(ctx context.Context, chain *config.ChainConfig, signerAddress string, hash []byte, signature string) (string, error) {
+	if !common.IsHexAddress(signerAddress) {
+		return "", fmt.Errorf("invalid signer address: %q", signerAddress)
+	}
+	signer := common.HexToAddress(signerAddress)
+
+	client, err := ChainClient(chain)
+	if err != nil {
+		return "", err
+	}
+	isContract, err := client.IsContractAddress(ctx, signer)
+	if err != nil {
+		return "", err
+	}
+
+	if !isContract {
+		recovered, err := recoverSigner(hash, signature)
+		if err != nil {
+			return "", err
+		}
+		if common.HexToAddress(recovered) != signer {
+			return "", fmt.Errorf("签名地址 %s 与声明的签名者 %s 不一致", recovered, signer.Hex())
+		}
+		return signer.Hex(), nil
+	}
+
+	// 合约钱包的签名格式由钱包自身定义（如Safe多签拼接的签名），不限制长度
+	sigBytes, err := hex.DecodeString(strings.TrimPrefix(signature, "0x"))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode signature: %w", err)
+	}
+	var digest [32]byte
+	copy(digest[:], hash)
+	valid, err := client.IsValidSignature(ctx, signer, digest, sigBytes)
+	if err != nil {
+		return "", err
+	}
+	if !valid {
+		return "", fmt.Errorf("合约钱包 %s 未认可该签名", signer.Hex())
+	}
+	return signer.Hex(), nil
+}
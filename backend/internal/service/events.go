@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// DataSubmittedEvent 合约的DataSubmitted事件
+type DataSubmittedEvent struct {
+	Pid       [32]byte
+	Did       [32]byte
+	Submitter common.Address
+	Timestamp *big.Int
+	Raw       types.Log
+}
+
+// ProjectRegisteredEvent 合约的ProjectRegistered事件
+type ProjectRegisteredEvent struct {
+	Pid         [32]byte
+	Submitter   common.Address
+	Description string
+	Raw         types.Log
+}
+
+// EventTopics 返回索引器关注的事件签名（DataSubmitted、ProjectRegistered）
+func (oc *OracleClient) EventTopics() []common.Hash {
+	return []common.Hash{
+		oc.contractABI.Events["DataSubmitted"].ID,
+		oc.contractABI.Events["ProjectRegistered"].ID,
+	}
+}
+
+// FilterEvents 获取区块范围内合约的DataSubmitted和ProjectRegistered日志
+func (oc *OracleClient) FilterEvents(ctx context.Context, fromBlock, toBlock uint64) ([]types.Log, error) {
+	query := ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(fromBlock),
+		ToBlock:   new(big.Int).SetUint64(toBlock),
+		Addresses: []common.Address{oc.contractAddress},
+		Topics:    [][]common.Hash{oc.EventTopics()},
+	}
+
+	var logs []types.Log
+	err := oc.withClient(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		var err error
+		logs, err = client.FilterLogs(ctx, query)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter logs: %w", err)
+	}
+	return logs, nil
+}
+
+// HeaderByNumber 获取指定区块的区块头
+func (oc *OracleClient) HeaderByNumber(ctx context.Context, number uint64) (*types.Header, error) {
+	var header *types.Header
+	err := oc.withClient(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		var err error
+		header, err = client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get header %d: %w", number, err)
+	}
+	return header, nil
+}
+
+// ParseDataSubmitted 解析DataSubmitted事件日志
+func (oc *OracleClient) ParseDataSubmitted(log types.Log) (*DataSubmittedEvent, error) {
+	event := oc.contractABI.Events["DataSubmitted"]
+	if len(log.Topics) != 4 || log.Topics[0] != event.ID {
+		return nil, errors.New("not a DataSubmitted log")
+	}
+
+	values, err := event.Inputs.NonIndexed().Unpack(log.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack DataSubmitted: %w", err)
+	}
+	timestamp, ok := values[0].(*big.Int)
+	if !ok {
+		return nil, errors.New("invalid DataSubmitted timestamp")
+	}
+
+	return &DataSubmittedEvent{
+		Pid:       log.Topics[1],
+		Did:       log.Topics[2],
+		Submitter: common.BytesToAddress(log.Topics[3].Bytes()),
+		Timestamp: timestamp,
+		Raw:       log,
+	}, nil
+}
+
+// ParseProjectRegistered 解析ProjectRegistered事件日志
+func (oc *OracleClient) ParseProjectRegistered(log types.Log) (*ProjectRegisteredEvent, error) {
+	event := oc.contractABI.Events["ProjectRegistered"]
+	if len(log.Topics) != 3 || log.Topics[0] != event.ID {
+		return nil, errors.New("not a ProjectRegistered log")
+	}
+
+	values, err := event.Inputs.NonIndexed().Unpack(log.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack ProjectRegistered: %w", err)
+	}
+	description, ok := values[0].(string)
+	if !ok {
+		return nil, errors.New("invalid ProjectRegistered description")
+	}
+
+	return &ProjectRegisteredEvent{
+		Pid:         log.Topics[1],
+		Submitter:   common.BytesToAddress(log.Topics[2].Bytes()),
+		Description: description,
+		Raw:         log,
+	}, nil
+}
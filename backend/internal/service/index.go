@@ -0,0 +1,58 @@
+package service
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"oracle-backend/internal/config"
+	"oracle-backend/internal/store"
+)
+
+// indexFreshIntervals 索引器在这么多个轮询间隔内追上过已确认区块时，读取接口使用索引
+const indexFreshIntervals = 3
+
+// indexReader 链上事件索引，由main在启动索引器时设置；未设置时读取接口直接调用合约
+var indexReader store.IndexReader
+
+// indexSyncedAt 每条链的索引器最近一次追上最新已确认区块的时间，key: chainID
+var indexSyncedAt sync.Map
+
+// SetIndexReader 设置读取接口使用的链上事件索引
+func SetIndexReader(r store.IndexReader) {
+	indexReader = r
+}
+
+// MarkIndexSynced 索引器追上最新的已确认区块后调用，之后一段时间内读取接口使用索引
+func MarkIndexSynced(chainID uint64, at time.Time) {
+	indexSyncedAt.Store(chainID, at)
+}
+
+// indexCaughtUp 链的索引是否可用于读取接口：索引器在最近几个轮询间隔内追上过最新的已确认区块
+// 索引只包含已确认的区块，尚未达到确认数的数据不会出现在索引中
+func indexCaughtUp(chain *config.ChainConfig) bool {
+	if indexReader == nil {
+		return false
+	}
+	at, ok := indexSyncedAt.Load(chain.ChainID)
+	return ok && time.Since(at.(time.Time)) <= indexFreshIntervals*chain.PollInterval()
+}
+
+// indexedProjectIDs 按注册顺序返回索引中链上的所有项目ID
+func indexedProjectIDs(chain *config.ChainConfig) ([][32]byte, error) {
+	indexed, err := indexReader.IndexedProjects(chain.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(indexed, func(i, j int) bool { return indexed[i].BlockNumber < indexed[j].BlockNumber })
+
+	pids := make([][32]byte, 0, len(indexed))
+	for _, p := range indexed {
+		pid, err := HexToBytes32(p.Pid)
+		if err != nil {
+			return nil, err
+		}
+		pids = append(pids, pid)
+	}
+	return pids, nil
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"oracle-backend/internal/models"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// oracleDataTuple 合约OracleData结构体的ABI解码结果
+type oracleDataTuple struct {
+	Pid        [32]byte
+	Did        [32]byte
+	CoreData   []byte
+	DataHash   [32]byte
+	Submitter  common.Address
+	SubmitTime *big.Int
+}
+
+// projectConfigTuple 合约ProjectConfig结构体的ABI解码结果
+type projectConfigTuple struct {
+	IsActive             bool
+	Description          []byte
+	AuthorizedSubmitters []common.Address
+	DataTTL              *big.Int
+}
+
+// call 在最新区块上调用合约的只读函数并返回解码后的结果
+func (oc *OracleClient) call(ctx context.Context, method string, args ...interface{}) ([]interface{}, error) {
+	return oc.callAt(ctx, nil, method, args...)
+}
+
+// callAt 在指定区块的状态上调用合约的只读函数，block为nil时使用最新区块
+func (oc *OracleClient) callAt(ctx context.Context, block *big.Int, method string, args ...interface{}) ([]interface{}, error) {
+	callData, err := oc.contractABI.Pack(method, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack call data for %s: %w", method, err)
+	}
+
+	result, err := oc.callContractAt(ctx, ethereum.CallMsg{
+		To:   &oc.contractAddress,
+		Data: callData,
+	}, block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", method, err)
+	}
+
+	out, err := oc.contractABI.Unpack(method, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack %s result: %w", method, err)
+	}
+	return out, nil
+}
+
+// callRecord 在最新区块上调用返回OracleData结构体的合约函数
+func (oc *OracleClient) callRecord(ctx context.Context, method string, args ...interface{}) (*models.OracleRecord, error) {
+	return oc.callRecordAt(ctx, nil, method, args...)
+}
+
+// callRecordAt 在指定区块的状态上调用返回OracleData结构体的合约函数，block为nil时使用最新区块
+func (oc *OracleClient) callRecordAt(ctx context.Context, block *big.Int, method string, args ...interface{}) (*models.OracleRecord, error) {
+	out, err := oc.callAt(ctx, block, method, args...)
+	if err != nil {
+		return nil, err
+	}
+	data := abi.ConvertType(out[0], new(oracleDataTuple)).(*oracleDataTuple)
+
+	return &models.OracleRecord{
+		Pid:        data.Pid,
+		Did:        data.Did,
+		CoreData:   data.CoreData,
+		DataHash:   data.DataHash,
+		Submitter:  data.Submitter,
+		SubmitTime: data.SubmitTime,
+	}, nil
+}
+
+// callBytes32Array 调用返回bytes32[]的合约函数
+func (oc *OracleClient) callBytes32Array(ctx context.Context, method string, args ...interface{}) ([][32]byte, error) {
+	out, err := oc.call(ctx, method, args...)
+	if err != nil {
+		return nil, err
+	}
+	return *abi.ConvertType(out[0], new([][32]byte)).(*[][32]byte), nil
+}
+
+// GetData 获取指定项目和数据ID的数据记录
+func (oc *OracleClient) GetData(ctx context.Context, pid, did [32]byte) (*models.OracleRecord, error) {
+	return oc.callRecord(ctx, "getData", pid, did)
+}
+
+// GetDataAt 获取指定项目和数据ID在某个区块时的数据记录，节点须保留该区块的状态
+func (oc *OracleClient) GetDataAt(ctx context.Context, pid, did [32]byte, block uint64) (*models.OracleRecord, error) {
+	return oc.callRecordAt(ctx, new(big.Int).SetUint64(block), "getData", pid, did)
+}
+
+// GetLatestData 获取项目最新的数据记录
+func (oc *OracleClient) GetLatestData(ctx context.Context, pid [32]byte) (*models.OracleRecord, error) {
+	return oc.callRecord(ctx, "getLatestData", pid)
+}
+
+// GetLatestDataByYearMonth 获取项目在指定年月内最新的数据记录
+func (oc *OracleClient) GetLatestDataByYearMonth(ctx context.Context, pid [32]byte, year uint16, month uint8) (*models.OracleRecord, error) {
+	return oc.callRecord(ctx, "getLatestDataByYearMonth", pid, year, month)
+}
+
+// GetLatestDataId 获取项目最新的数据ID
+func (oc *OracleClient) GetLatestDataId(ctx context.Context, pid [32]byte) ([32]byte, error) {
+	out, err := oc.call(ctx, "getLatestDataId", pid)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return *abi.ConvertType(out[0], new([32]byte)).(*[32]byte), nil
+}
+
+// GetDataIds 获取项目的所有数据ID
+func (oc *OracleClient) GetDataIds(ctx context.Context, pid [32]byte) ([][32]byte, error) {
+	return oc.callBytes32Array(ctx, "getDataIds", pid)
+}
+
+// GetDataIdsByYearMonth 获取项目在指定年月内的数据ID
+func (oc *OracleClient) GetDataIdsByYearMonth(ctx context.Context, pid [32]byte, year uint16, month uint8) ([][32]byte, error) {
+	return oc.callBytes32Array(ctx, "getDataIdsByYearMonth", pid, year, month)
+}
+
+// GetDataIdsByPrefix 获取项目中以指定前缀开头的数据ID
+func (oc *OracleClient) GetDataIdsByPrefix(ctx context.Context, pid, prefix [32]byte) ([][32]byte, error) {
+	return oc.callBytes32Array(ctx, "getDataIdsByPrefix", pid, prefix)
+}
+
+// GetProjectConfig 获取项目配置
+func (oc *OracleClient) GetProjectConfig(ctx context.Context, pid [32]byte) (*models.ProjectConfig, error) {
+	out, err := oc.call(ctx, "getProjectConfig", pid)
+	if err != nil {
+		return nil, err
+	}
+	cfg := abi.ConvertType(out[0], new(projectConfigTuple)).(*projectConfigTuple)
+
+	return &models.ProjectConfig{
+		IsActive:             cfg.IsActive,
+		Description:          string(cfg.Description),
+		AuthorizedSubmitters: cfg.AuthorizedSubmitters,
+		DataTTL:              cfg.DataTTL,
+	}, nil
+}
+
+// GetAllProjects 获取所有已注册项目的ID
+func (oc *OracleClient) GetAllProjects(ctx context.Context) ([][32]byte, error) {
+	return oc.callBytes32Array(ctx, "getAllProjects")
+}
+
+// GetProjectsByAddress 获取地址有权限提交数据的项目ID
+func (oc *OracleClient) GetProjectsByAddress(ctx context.Context, addr common.Address) ([][32]byte, error) {
+	return oc.callBytes32Array(ctx, "getProjectsByAddress", addr)
+}
+
+// GetCoreData 获取数据记录的核心数据
+func (oc *OracleClient) GetCoreData(ctx context.Context, pid, did [32]byte) ([]byte, error) {
+	out, err := oc.call(ctx, "getCoreData", pid, did)
+	if err != nil {
+		return nil, err
+	}
+	return *abi.ConvertType(out[0], new([]byte)).(*[]byte), nil
+}
+
+// GetDataHash 获取数据记录的文件哈希
+func (oc *OracleClient) GetDataHash(ctx context.Context, pid, did [32]byte) ([32]byte, error) {
+	out, err := oc.call(ctx, "getDataHash", pid, did)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return *abi.ConvertType(out[0], new([32]byte)).(*[32]byte), nil
+}
+
+// EncodeYearMonthDayToDid 调用合约的纯函数将日期编码为数据ID
+func (oc *OracleClient) EncodeYearMonthDayToDid(ctx context.Context, year uint16, month, day uint8) ([32]byte, error) {
+	out, err := oc.call(ctx, "encodeYearMonthDayToDid", year, month, day)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return *abi.ConvertType(out[0], new([32]byte)).(*[32]byte), nil
+}
+
+// DecodeDidToYearMonthDay 调用合约的纯函数将数据ID解码为日期
+func (oc *OracleClient) DecodeDidToYearMonthDay(ctx context.Context, did [32]byte) (uint16, uint8, uint8, error) {
+	out, err := oc.call(ctx, "decodeDidToYearMonthDay", did)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return *abi.ConvertType(out[0], new(uint16)).(*uint16),
+		*abi.ConvertType(out[1], new(uint8)).(*uint8),
+		*abi.ConvertType(out[2], new(uint8)).(*uint8),
+		nil
+}
+
+// IsValidYearMonthDayDid 调用合约的纯函数检查数据ID是否为有效日期
+func (oc *OracleClient) IsValidYearMonthDayDid(ctx context.Context, did [32]byte) (bool, error) {
+	out, err := oc.call(ctx, "isValidYearMonthDayDid", did)
+	if err != nil {
+		return false, err
+	}
+	return *abi.ConvertType(out[0], new(bool)).(*bool), nil
+}
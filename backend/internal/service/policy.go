@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"oracle-backend/internal/config"
+	"oracle-backend/internal/models"
+	"oracle-backend/internal/store"
+)
+
+const (
+	// sniffLength http.DetectContentType最多读取的字节数
+	sniffLength = 512
+	// uploadFormOverhead 上传请求中文件以外的表单字段和multipart头允许的字节数
+	uploadFormOverhead = 1 << 20
+)
+
+// uploadPolicyStore 项目上传策略存储，由main在启动时设置
+var uploadPolicyStore store.UploadPolicyStore
+
+// uploadLimits 服务端的上传文件限制，由main在启动时设置
+var uploadLimits = &config.UploadLimits{
+	MaxFileSize:  config.DefaultUploadMaxFileSize,
+	MaxFiles:     config.DefaultUploadMaxFiles,
+	MaxTotalSize: config.DefaultUploadMaxTotalSize,
+}
+
+// SetUploadPolicyStore 设置服务层使用的项目上传策略存储
+func SetUploadPolicyStore(ps store.UploadPolicyStore) {
+	uploadPolicyStore = ps
+}
+
+// SetUploadLimits 设置服务端的上传文件限制
+func SetUploadLimits(limits *config.UploadLimits) {
+	uploadLimits = limits
+}
+
+// MaxUploadRequestSize 上传请求体的字节数上限（服务端的文件总大小上限加表单字段），项目的上传策略在读取文件时检查
+func MaxUploadRequestSize() int64 {
+	return uploadLimits.MaxTotalSize + uploadFormOverhead
+}
+
+// MaxUploadFieldsSize 上传请求中文件之前的表单字段允许的总字节数
+func MaxUploadFieldsSize() int64 {
+	return uploadFormOverhead
+}
+
+// textDataTypes 内容识别为text/plain时可以采用的声明类型
+var textDataTypes = []string{
+	"text/csv",
+	"text/tab-separated-values",
+	"text/markdown",
+	"application/json",
+	"application/x-ndjson",
+	"application/yaml",
+	"text/yaml",
+}
+
+// detectedType 按文件内容识别的类型和客户端声明的类型
+type detectedType struct {
+	// sniffed 按文件开头的字节（magic bytes）识别的类型
+	sniffed string
+	// declared 客户端在multipart头中声明的类型
+	declared string
+	// contentType 用于策略检查和下载的类型：通常为sniffed，内容识别只能给出笼统类型时采用与之相容的声明类型
+	contentType string
+}
+
+// GetUploadPolicy 获取项目在指定链上生效的上传策略，项目未设置的项使用服务端的默认限制
+func GetUploadPolicy(chainId, projectId string) (*models.UploadPolicy, error) {
+	chain, err := ResolveChain(chainId)
+	if err != nil {
+		return nil, err
+	}
+	pid, err := ParseProjectID(projectId)
+	if err != nil {
+		return nil, err
+	}
+	return uploadPolicy(chain.ChainID, Bytes32ToHex(pid))
+}
+
+// UpdateUploadPolicy 修改项目在指定链上的上传策略，address须为项目的授权提交者，各项不能超过服务端的限制
+func UpdateUploadPolicy(ctx context.Context, address, chainId, projectId string, req *models.UpdateUploadPolicyRequest) (*models.UploadPolicy, error) {
+	if uploadPolicyStore == nil {
+		return nil, errors.New("upload policy store is not initialized")
+	}
+	chain, err := ResolveChain(chainId)
+	if err != nil {
+		return nil, err
+	}
+	pid, err := ParseProjectID(projectId)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case req.MaxFileSize < 0 || req.MaxFileSize > uploadLimits.MaxFileSize:
+		return nil, fmt.Errorf("maxFileSize must be between 0 and %d", uploadLimits.MaxFileSize)
+	case req.MaxFiles < 0 || req.MaxFiles > uploadLimits.MaxFiles:
+		return nil, fmt.Errorf("maxFiles must be between 0 and %d", uploadLimits.MaxFiles)
+	case req.MaxTotalSize < 0 || req.MaxTotalSize > uploadLimits.MaxTotalSize:
+		return nil, fmt.Errorf("maxTotalSize must be between 0 and %d", uploadLimits.MaxTotalSize)
+	}
+	var allowedTypes []string
+	for _, t := range req.AllowedTypes {
+		t, err := parseTypePattern(t)
+		if err != nil {
+			return nil, err
+		}
+		if len(uploadLimits.AllowedTypes) > 0 && !typeAllowed(uploadLimits.AllowedTypes, t) {
+			return nil, fmt.Errorf("type %s is not allowed by the server", t)
+		}
+		if !slices.Contains(allowedTypes, t) {
+			allowedTypes = append(allowedTypes, t)
+		}
+	}
+
+	isAuthorized, err := CheckContractAuthorization(ctx, chain, address, Bytes32ToHex(pid))
+	if err != nil {
+		return nil, fmt.Errorf("合约权限检查失败: %w", err)
+	}
+	if !isAuthorized {
+		return nil, fmt.Errorf("%w: %s 不是项目 %s 的所有者或授权提交者", ErrAccessDenied, address, projectId)
+	}
+
+	p := models.UploadPolicy{
+		ChainID:      chain.ChainID,
+		Pid:          Bytes32ToHex(pid),
+		AllowedTypes: allowedTypes,
+		MaxFileSize:  req.MaxFileSize,
+		MaxFiles:     req.MaxFiles,
+		MaxTotalSize: req.MaxTotalSize,
+		UpdatedBy:    address,
+		UpdatedAt:    time.Now(),
+	}
+	if err := uploadPolicyStore.SaveUploadPolicy(p); err != nil {
+		return nil, err
+	}
+	return uploadPolicy(p.ChainID, p.Pid)
+}
+
+// checkFileType 按文件开头的字节和客户端声明的类型识别文件类型，类型须同时满足服务端和项目允许的类型
+func checkFileType(policy *models.UploadPolicy, head []byte, declared string) (*detectedType, error) {
+	dt := &detectedType{
+		sniffed:  http.DetectContentType(head),
+		declared: declared,
+	}
+	dt.contentType = refineContentType(dt.sniffed, dt.declared)
+	if !typeAllowed(uploadLimits.AllowedTypes, dt.contentType) || !typeAllowed(policy.AllowedTypes, dt.contentType) {
+		return dt, fmt.Errorf("文件类型 %s 不在项目允许的类型中（声明的类型: %s）", dt.contentType, dt.declared)
+	}
+	return dt, nil
+}
+
+// refineContentType 内容识别只能给出笼统的类型时（纯文本、zip容器），采用与之相容的声明类型，否则使用识别的类型
+// 例如CSV和JSON识别为text/plain，xlsx和odt识别为application/zip
+func refineContentType(sniffed, declared string) string {
+	sniffedType, _, err := mime.ParseMediaType(sniffed)
+	if err != nil {
+		return sniffed
+	}
+	declaredType, _, err := mime.ParseMediaType(declared)
+	if err != nil {
+		return sniffed
+	}
+
+	switch sniffedType {
+	case "text/plain":
+		// 只采用不会被浏览器当作页面或脚本执行的文本数据类型
+		if slices.Contains(textDataTypes, declaredType) {
+			return declaredType
+		}
+	case "application/zip":
+		if strings.HasPrefix(declaredType, "application/vnd.openxmlformats-officedocument.") ||
+			strings.HasPrefix(declaredType, "application/vnd.oasis.opendocument.") {
+			return declaredType
+		}
+	}
+	return sniffed
+}
+
+// typeAllowed 检查类型是否匹配允许列表中的任一项，列表为空时不限制
+func typeAllowed(allowed []string, contentType string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(contentType)
+	}
+	return slices.ContainsFunc(allowed, func(pattern string) bool {
+		pattern = strings.ToLower(pattern)
+		if major, ok := strings.CutSuffix(pattern, "/*"); ok {
+			return strings.HasPrefix(mediaType, major+"/")
+		}
+		return pattern == mediaType
+	})
+}
+
+// parseTypePattern 解析允许列表中的类型（type/subtype或type/*），返回小写形式
+func parseTypePattern(pattern string) (string, error) {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	if major, ok := strings.CutSuffix(pattern, "/*"); ok && major != "" && !strings.ContainsAny(major, "/*") {
+		return pattern, nil
+	}
+	mediaType, params, err := mime.ParseMediaType(pattern)
+	if err != nil || len(params) > 0 || !strings.Contains(mediaType, "/") || strings.Contains(mediaType, "*") {
+		return "", fmt.Errorf("invalid content type: %q", pattern)
+	}
+	return mediaType, nil
+}
+
+// uploadPolicy 获取项目生效的上传策略，项目未设置的项使用服务端的默认限制
+func uploadPolicy(chainID uint64, pid string) (*models.UploadPolicy, error) {
+	if uploadPolicyStore == nil {
+		return nil, errors.New("upload policy store is not initialized")
+	}
+	p, err := uploadPolicyStore.UploadPolicy(chainID, pid)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		p = &models.UploadPolicy{ChainID: chainID, Pid: pid}
+	}
+	if len(p.AllowedTypes) == 0 {
+		p.AllowedTypes = uploadLimits.AllowedTypes
+	}
+	if p.MaxFileSize == 0 {
+		p.MaxFileSize = uploadLimits.MaxFileSize
+	}
+	if p.MaxFiles == 0 {
+		p.MaxFiles = uploadLimits.MaxFiles
+	}
+	if p.MaxTotalSize == 0 {
+		p.MaxTotalSize = uploadLimits.MaxTotalSize
+	}
+	return p, nil
+}
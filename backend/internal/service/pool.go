@@ -0,0 +1,77 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+
+	"oracle-backend/internal/config"
+)
+
+// ClientPool 按链ID管理长连接的OracleClient
+// 每条链只建立一个客户端并在所有请求间复用，连接断开时由OracleClient自动重连
+type ClientPool struct {
+	registry *config.ChainRegistry
+
+	// mu 只保护entries，建立连接时不持有，一条链的节点不可达时不影响其他链
+	mu      sync.Mutex
+	entries map[uint64]*poolEntry
+}
+
+// poolEntry 单条链的客户端，mu保证同一条链同一时间只建立一次连接
+type poolEntry struct {
+	mu     sync.Mutex
+	client *OracleClient
+}
+
+// NewClientPool 创建客户端连接池，客户端在第一次使用时建立
+func NewClientPool(registry *config.ChainRegistry) *ClientPool {
+	return &ClientPool{
+		registry: registry,
+		entries:  make(map[uint64]*poolEntry),
+	}
+}
+
+// Get 获取指定链的客户端，不存在时创建；建立连接失败时下次调用重新尝试
+func (p *ClientPool) Get(chainID uint64) (*OracleClient, error) {
+	chain, err := p.registry.Get(chainID)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	entry, ok := p.entries[chainID]
+	if !ok {
+		entry = &poolEntry{}
+		p.entries[chainID] = entry
+	}
+	p.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.client != nil {
+		return entry.client, nil
+	}
+	client, err := NewOracleClientForChain(chain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client for chain %d: %w", chainID, err)
+	}
+	entry.client = client
+	return client, nil
+}
+
+// Close 关闭连接池中的所有客户端，正在建立的连接在完成后关闭
+func (p *ClientPool) Close() {
+	p.mu.Lock()
+	entries := p.entries
+	p.entries = make(map[uint64]*poolEntry)
+	p.mu.Unlock()
+
+	for _, entry := range entries {
+		entry.mu.Lock()
+		if entry.client != nil {
+			entry.client.Close()
+			entry.client = nil
+		}
+		entry.mu.Unlock()
+	}
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"oracle-backend/internal/config"
+	"oracle-backend/internal/models"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrProjectNotFound 链上不存在指定的项目
+var ErrProjectNotFound = errors.New("project not found on chain")
+
+// projectCacheEntry 一条缓存的查询结果
+type projectCacheEntry struct {
+	value     interface{}
+	expiresAt time.Time
+}
+
+// projectCache 按链缓存项目目录的查询结果，缓存时间由链配置的ProjectCacheTTL决定
+// 项目注册和配置变更不频繁，缓存可以避免仪表盘刷新时重复请求RPC节点
+type projectCache struct {
+	mu     sync.Mutex
+	chains map[uint64]map[string]projectCacheEntry
+}
+
+// projects 全局的项目目录缓存
+var projects = &projectCache{chains: make(map[uint64]map[string]projectCacheEntry)}
+
+// get 返回未过期的缓存值，不存在或已过期时调用load加载并缓存（加载失败时不缓存）
+func (pc *projectCache) get(chain *config.ChainConfig, key string, load func() (interface{}, error)) (interface{}, error) {
+	now := time.Now()
+	pc.mu.Lock()
+	entry, ok := pc.chains[chain.ChainID][key]
+	pc.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.value, nil
+	}
+
+	value, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	entries, ok := pc.chains[chain.ChainID]
+	if !ok {
+		entries = make(map[string]projectCacheEntry)
+		pc.chains[chain.ChainID] = entries
+	}
+	// 顺便清理这条链上已过期的条目，避免按地址查询的缓存无限增长
+	for k, e := range entries {
+		if !now.Before(e.expiresAt) {
+			delete(entries, k)
+		}
+	}
+	entries[key] = projectCacheEntry{value: value, expiresAt: now.Add(chain.ProjectCacheTTL())}
+	return value, nil
+}
+
+// ListProjects 获取链上所有已注册项目及其配置，索引已追上时项目列表取自ProjectRegistered事件索引
+func ListProjects(ctx context.Context, chain *config.ChainConfig) ([]*models.ProjectInfo, error) {
+	value, err := projects.get(chain, "all", func() (interface{}, error) {
+		if indexCaughtUp(chain) {
+			return indexedProjectIDs(chain)
+		}
+		client, err := ChainClient(chain)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetAllProjects(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return projectInfos(ctx, chain, value.([][32]byte))
+}
+
+// ListProjectsByAddress 获取地址有权限提交数据的项目及其配置
+// 授权提交者的变更没有对应的事件索引，始终调用合约（结果按链缓存）
+func ListProjectsByAddress(ctx context.Context, chain *config.ChainConfig, address string) ([]*models.ProjectInfo, error) {
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("invalid address: %q", address)
+	}
+	addr := common.HexToAddress(address)
+
+	value, err := projects.get(chain, "address/"+strings.ToLower(addr.Hex()), func() (interface{}, error) {
+		client, err := ChainClient(chain)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetProjectsByAddress(ctx, addr)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return projectInfos(ctx, chain, value.([][32]byte))
+}
+
+// GetProject 获取项目在合约中的配置
+func GetProject(ctx context.Context, chain *config.ChainConfig, pid [32]byte) (*models.ProjectInfo, error) {
+	value, err := projects.get(chain, "project/"+Bytes32ToHex(pid), func() (interface{}, error) {
+		client, err := ChainClient(chain)
+		if err != nil {
+			return nil, err
+		}
+		cfg, err := client.GetProjectConfig(ctx, pid)
+		if err != nil {
+			if errors.Is(recordCallError(err), ErrRecordNotFound) {
+				return nil, fmt.Errorf("%w: %v", ErrProjectNotFound, err)
+			}
+			return nil, err
+		}
+		// 合约对未注册的项目返回零值配置
+		if !cfg.IsActive && cfg.Description == "" && len(cfg.AuthorizedSubmitters) == 0 {
+			return nil, ErrProjectNotFound
+		}
+		return cfg, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	info := &models.ProjectInfo{
+		ChainID:       chain.ChainID,
+		Pid:           Bytes32ToHex(pid),
+		ProjectConfig: *value.(*models.ProjectConfig),
+	}
+	if name := Bytes32ToString(pid); name != "" && utf8.ValidString(name) {
+		info.ProjectID = name
+	}
+	return info, nil
+}
+
+// projectInfos 获取一组项目的配置
+func projectInfos(ctx context.Context, chain *config.ChainConfig, pids [][32]byte) ([]*models.ProjectInfo, error) {
+	infos := make([]*models.ProjectInfo, 0, len(pids))
+	for _, pid := range pids {
+		info, err := GetProject(ctx, chain, pid)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get project %s: %w", Bytes32ToHex(pid), err)
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
@@ -0,0 +1,378 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"oracle-backend/internal/config"
+	"oracle-backend/internal/models"
+	"oracle-backend/internal/storage"
+)
+
+// ErrRecordNotFound 链上不存在指定的数据记录
+var ErrRecordNotFound = errors.New("record not found on chain")
+
+// VerifyRecord 对账单条数据记录，须由项目的所有者或授权提交者调用
+// 文件哈希优先取自后台对账任务的结果，对象的大小和修改时间未变化时不重新读取文件
+func VerifyRecord(ctx context.Context, address string, chain *config.ChainConfig, pid, did [32]byte) (*models.ReconcileResult, error) {
+	isAuthorized, err := CheckContractAuthorization(ctx, chain, address, Bytes32ToHex(pid))
+	if err != nil {
+		return nil, fmt.Errorf("合约权限检查失败: %w", err)
+	}
+	if !isAuthorized {
+		return nil, fmt.Errorf("%w: %s 不是项目 %s 的所有者或授权提交者", ErrAccessDenied, address, Bytes32ToHex(pid))
+	}
+	return verifyRecord(ctx, chain, pid, did, blobHashes.cached)
+}
+
+// verifyRecord 按前端规则从本地文件重新计算dataHash，并与合约getDataHash比较，文件哈希由hashOf计算
+func verifyRecord(ctx context.Context, chain *config.ChainConfig, pid, did [32]byte,
+	hashOf func(ctx context.Context, key string) (string, error)) (*models.ReconcileResult, error) {
+	if uploadRepo == nil || blobStore == nil {
+		return nil, errors.New("upload repository or storage backend is not initialized")
+	}
+	client, err := ChainClient(chain)
+	if err != nil {
+		return nil, err
+	}
+
+	onChain, err := client.GetDataHash(ctx, pid, did)
+	if err != nil {
+		return nil, err
+	}
+	if onChain == ([32]byte{}) {
+		return nil, ErrRecordNotFound
+	}
+
+	result := &models.ReconcileResult{
+		ChainID:         chain.ChainID,
+		Pid:             Bytes32ToHex(pid),
+		Did:             Bytes32ToHex(did),
+		OnChainDataHash: Bytes32ToHex(onChain),
+	}
+
+	manifest, err := uploadRepo.Submission(chain.ChainID, result.Pid, result.Did)
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil {
+		result.Status = models.ReconcileMissingOnDisk
+		result.Details = "no uploaded files recorded for this record"
+		return result, nil
+	}
+
+	for _, file := range manifest.Files {
+		actual, err := hashOf(ctx, file.Path)
+		if errors.Is(err, storage.ErrNotFound) {
+			result.MissingFiles = append(result.MissingFiles, file.Path)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if actual != normalizeHash(file.FileHash) {
+			result.MismatchedFiles = append(result.MismatchedFiles, file.Path)
+		}
+	}
+
+	expected, err := ComputeDataHash(manifest.FileHashes)
+	if err != nil {
+		return nil, err
+	}
+	result.ExpectedDataHash = Bytes32ToHex(expected)
+
+	switch {
+	case len(result.MissingFiles) > 0:
+		result.Status = models.ReconcileMissingOnDisk
+	case len(result.MismatchedFiles) > 0:
+		result.Status = models.ReconcileMismatched
+		result.Details = "file content does not match the recorded hash"
+	case expected != onChain:
+		result.Status = models.ReconcileMismatched
+		result.Details = "recomputed dataHash does not match on-chain dataHash"
+	default:
+		result.Status = models.ReconcileMatched
+	}
+	return result, nil
+}
+
+// Reconcile 对所有已记录的提交执行对账，并找出没有对应提交记录的本地文件
+func Reconcile(ctx context.Context) (*models.ReconcileReport, error) {
+	if uploadRepo == nil || blobStore == nil {
+		return nil, errors.New("upload repository or storage backend is not initialized")
+	}
+	report := &models.ReconcileReport{StartedAt: time.Now()}
+	// 对账任务总是重新读取文件，并用本次的结果替换单条对账使用的哈希缓存
+	hashes := newBlobHashCache()
+
+	manifests, err := uploadRepo.Submissions()
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool)
+	for _, m := range manifests {
+		for _, file := range m.Files {
+			known[file.Path] = true
+		}
+
+		chain, err := chainRegistry.Get(m.ChainID)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%d/%s/%s: %v", m.ChainID, m.Pid, m.Did, err))
+			continue
+		}
+		pid, _ := HexToBytes32(m.Pid)
+		did, _ := HexToBytes32(m.Did)
+
+		result, err := verifyRecord(ctx, chain, pid, did, hashes.fresh)
+		if errors.Is(err, ErrRecordNotFound) {
+			// 文件已上传但尚未上链
+			continue
+		}
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%d/%s/%s: %v", m.ChainID, m.Pid, m.Did, err))
+			continue
+		}
+		report.Results = append(report.Results, *result)
+	}
+
+	blobs, err := blobStore.List(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan uploads: %w", err)
+	}
+	for _, blob := range blobs {
+		if !known[blob.Key] {
+			report.OrphanedFiles = append(report.OrphanedFiles, blob.Key)
+		}
+	}
+
+	blobHashes.replace(hashes)
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+var (
+	reportMu   sync.RWMutex
+	lastReport *models.ReconcileReport
+)
+
+// StartReconciler 按固定间隔在后台执行对账，结果可通过LastReconcileReport获取
+func StartReconciler(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			report, err := Reconcile(ctx)
+			if err != nil {
+				log.Printf("reconcile: %v", err)
+			} else {
+				reportMu.Lock()
+				lastReport = report
+				reportMu.Unlock()
+				logReconcileReport(report)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// LastReconcileReport 返回最近一次对账报告，尚未执行过时返回nil
+func LastReconcileReport() *models.ReconcileReport {
+	reportMu.RLock()
+	defer reportMu.RUnlock()
+	return lastReport
+}
+
+// ReconcileReportFor 返回最近一次对账报告中地址是所有者或授权提交者的项目的部分，尚未执行过时返回nil
+// 对账结果、孤立文件和错误按key或消息开头的<chainId>/<pid>归属到项目，无法归属的条目不返回
+func ReconcileReportFor(ctx context.Context, address string) (*models.ReconcileReport, error) {
+	report := LastReconcileReport()
+	if report == nil {
+		return nil, nil
+	}
+
+	authorized := make(map[string]bool)
+	allowed := func(chainID uint64, pid string) (bool, error) {
+		key := fmt.Sprintf("%d/%s", chainID, strings.ToLower(pid))
+		if ok, cached := authorized[key]; cached {
+			return ok, nil
+		}
+		chain, err := chainRegistry.Get(chainID)
+		if err != nil {
+			// 对账后链已从配置中移除
+			authorized[key] = false
+			return false, nil
+		}
+		ok, err := CheckContractAuthorization(ctx, chain, address, pid)
+		if err != nil {
+			return false, err
+		}
+		authorized[key] = ok
+		return ok, nil
+	}
+	allowedPrefix := func(s string) (bool, error) {
+		chainID, pid, ok := reportProject(s)
+		if !ok {
+			return false, nil
+		}
+		return allowed(chainID, pid)
+	}
+
+	filtered := &models.ReconcileReport{
+		StartedAt:     report.StartedAt,
+		FinishedAt:    report.FinishedAt,
+		Results:       []models.ReconcileResult{},
+		OrphanedFiles: []string{},
+	}
+	for _, result := range report.Results {
+		ok, err := allowed(result.ChainID, result.Pid)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			filtered.Results = append(filtered.Results, result)
+		}
+	}
+	for _, key := range report.OrphanedFiles {
+		ok, err := allowedPrefix(key)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			filtered.OrphanedFiles = append(filtered.OrphanedFiles, key)
+		}
+	}
+	for _, msg := range report.Errors {
+		ok, err := allowedPrefix(msg)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			filtered.Errors = append(filtered.Errors, msg)
+		}
+	}
+	return filtered, nil
+}
+
+// reportProject 解析以<chainId>/<pid>/开头的对象key或错误消息
+func reportProject(s string) (uint64, string, bool) {
+	parts := strings.SplitN(s, "/", 3)
+	if len(parts) < 3 {
+		return 0, "", false
+	}
+	chainID, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, "", false
+	}
+	if _, err := HexToBytes32(parts[1]); err != nil {
+		return 0, "", false
+	}
+	return chainID, parts[1], true
+}
+
+// logReconcileReport 输出对账结果中的异常项
+func logReconcileReport(report *models.ReconcileReport) {
+	counts := make(map[models.ReconcileStatus]int)
+	for _, result := range report.Results {
+		counts[result.Status]++
+		if result.Status != models.ReconcileMatched {
+			log.Printf("reconcile: %d/%s/%s %s %s", result.ChainID, result.Pid, result.Did, result.Status, result.Details)
+		}
+	}
+	log.Printf("reconcile: %d matched, %d missing-on-disk, %d mismatched, %d orphaned files, %d errors",
+		counts[models.ReconcileMatched], counts[models.ReconcileMissingOnDisk], counts[models.ReconcileMismatched],
+		len(report.OrphanedFiles), len(report.Errors))
+}
+
+// blobHash 已计算的对象内容哈希，以及计算时对象的大小和修改时间
+type blobHash struct {
+	hash    string
+	size    int64
+	modTime time.Time
+}
+
+// blobHashCache 对象key到内容哈希的缓存，由后台对账任务整体替换
+type blobHashCache struct {
+	mu     sync.Mutex
+	hashes map[string]blobHash
+}
+
+func newBlobHashCache() *blobHashCache {
+	return &blobHashCache{hashes: make(map[string]blobHash)}
+}
+
+// blobHashes 单条对账使用的哈希缓存，避免每次请求都重新读取全部文件
+var blobHashes = newBlobHashCache()
+
+// replace 用另一次对账的结果替换缓存内容，已删除的对象随之移除
+func (c *blobHashCache) replace(other *blobHashCache) {
+	other.mu.Lock()
+	hashes := other.hashes
+	other.mu.Unlock()
+
+	c.mu.Lock()
+	c.hashes = hashes
+	c.mu.Unlock()
+}
+
+// cached 返回对象的内容哈希，对象的大小和修改时间与缓存一致时不重新读取
+func (c *blobHashCache) cached(ctx context.Context, key string) (string, error) {
+	info, err := blobStore.Stat(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	entry, ok := c.hashes[key]
+	c.mu.Unlock()
+	if ok && entry.size == info.Size && entry.modTime.Equal(info.ModTime) {
+		return entry.hash, nil
+	}
+	return c.fresh(ctx, key)
+}
+
+// fresh 重新读取对象并计算内容哈希，结果写入缓存
+func (c *blobHashCache) fresh(ctx context.Context, key string) (string, error) {
+	hash, info, err := hashBlob(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	c.hashes[key] = blobHash{hash: hash, size: info.Size, modTime: info.ModTime}
+	c.mu.Unlock()
+	return hash, nil
+}
+
+// hashBlob 计算存储后端中对象内容的SHA-256（小写十六进制，无0x前缀），并返回读取时的对象元数据
+func hashBlob(ctx context.Context, key string) (string, *storage.BlobInfo, error) {
+	r, info, err := blobStore.Get(ctx, key)
+	if err != nil {
+		return "", nil, err
+	}
+	defer r.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, r); err != nil {
+		return "", nil, fmt.Errorf("failed to hash %s: %w", key, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), info, nil
+}
+
+// normalizeHash 去除0x前缀并转换为小写
+func normalizeHash(hash string) string {
+	return strings.ToLower(strings.TrimPrefix(hash, "0x"))
+}
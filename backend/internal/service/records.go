@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"oracle-backend/internal/models"
+	"oracle-backend/internal/store"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// uploadRepo 上传元数据存储，由main在启动时设置
+var uploadRepo store.UploadRepository
+
+// SetUploadRepository 设置服务层使用的上传元数据存储
+func SetUploadRepository(repo store.UploadRepository) {
+	uploadRepo = repo
+}
+
+// RecordSubmission 持久化一次已验证提交的元数据：提交（项目+数据ID）对应的文件清单，以及每个文件的上传记录
+func RecordSubmission(ctx context.Context, submission *Submission, signatureDataStr string, results []*models.FileUploadResult) error {
+	if uploadRepo == nil {
+		return errors.New("upload repository is not initialized")
+	}
+	chain, projectId, pid, sigData := submission.Chain, submission.ProjectID, submission.Pid, submission.SigData
+
+	did, replace, err := checkResubmission(ctx, submission)
+	if err != nil {
+		return err
+	}
+
+	manifest := models.SubmissionManifest{
+		ChainID:    chain.ChainID,
+		ProjectID:  projectId,
+		Pid:        Bytes32ToHex(pid),
+		Did:        Bytes32ToHex(did),
+		DataDate:   sigData.DataDate,
+		FileHashes: sigData.FileHashes,
+		Signer:     submission.Signer,
+		CreatedAt:  time.Now(),
+	}
+	uploads := make([]models.UploadRecord, 0, len(results))
+	for _, result := range results {
+		manifest.Files = append(manifest.Files, models.SubmissionFile{
+			FileName: result.FileName,
+			FileHash: result.FileHash,
+			Path:     result.FilePath,
+		})
+		uploads = append(uploads, models.UploadRecord{
+			ChainID:             chain.ChainID,
+			ProjectID:           projectId,
+			Pid:                 manifest.Pid,
+			Did:                 manifest.Did,
+			DataDate:            sigData.DataDate,
+			FileName:            result.FileName,
+			FileSize:            result.FileSize,
+			FileHash:            result.FileHash,
+			ContentType:         result.ContentType,
+			SniffedContentType:  result.SniffedContentType,
+			DeclaredContentType: result.DeclaredContentType,
+			StoragePath:         result.FilePath, // FilePath即存储后端中的对象key
+			Signer:              result.Signer,
+			Signature:           submission.Signature,
+			SignedPayload:       signatureDataStr,
+			APIKeyID:            submission.APIKeyID,
+			CoreData:            hexutil.Encode(submission.CoreData),
+			CoreDataValues:      submission.CoreDataValues,
+			UploadTime:          result.UploadTime,
+		})
+	}
+
+	// 签名提交在同一事务中记录签名已使用并消费nonce
+	var sig *models.UsedSignature
+	var nonce string
+	if submission.replay != nil {
+		sig, nonce = &submission.replay.sig, submission.replay.nonce
+	}
+	return uploadRepo.SaveSubmission(manifest, uploads, sig, nonce, replace)
+}
+
+// ErrSubmissionExists 数据记录已有提交，且不能被本次提交替换
+var ErrSubmissionExists = store.ErrSubmissionExists
+
+// checkResubmission 检查数据记录能否接受本次提交，返回数据ID，以及记录尚未上链、已有提交可以被同一提交者替换
+// 已有提交时，只有原提交者可以在记录上链前重新提交；已上链的提交和其他地址的提交保留，避免审计数据丢失
+func checkResubmission(ctx context.Context, submission *Submission) ([32]byte, bool, error) {
+	chain, pid := submission.Chain, submission.Pid
+	client, err := ChainClient(chain)
+	if err != nil {
+		return [32]byte{}, false, err
+	}
+	did, err := ResolveDid(ctx, client, submission.SigData.DataDate)
+	if err != nil {
+		return [32]byte{}, false, fmt.Errorf("计算数据ID失败: %w", err)
+	}
+	onChain, err := client.GetDataHash(ctx, pid, did)
+	if err != nil {
+		return [32]byte{}, false, fmt.Errorf("查询链上记录失败: %w", err)
+	}
+	replace := onChain == ([32]byte{})
+
+	existing, err := uploadRepo.Submission(chain.ChainID, Bytes32ToHex(pid), Bytes32ToHex(did))
+	if err != nil {
+		return [32]byte{}, false, err
+	}
+	if existing == nil {
+		return did, replace, nil
+	}
+	if !replace {
+		return [32]byte{}, false, fmt.Errorf("%w: 数据 %s 已上链，不能重新提交", ErrSubmissionExists, submission.SigData.DataDate)
+	}
+	if existing.Signer != "" && !strings.EqualFold(existing.Signer, submission.Signer) {
+		return [32]byte{}, false, fmt.Errorf("%w: 数据 %s 已由 %s 提交", ErrSubmissionExists, submission.SigData.DataDate, existing.Signer)
+	}
+	return did, replace, nil
+}
+
+// ListProjectUploads 获取项目在指定链上的所有上传记录，只有项目的所有者或授权提交者可以查看
+// 上传记录包含所有文件（含尚未上链的文件）的文件名和存储路径，不按项目的下载可见性开放
+func ListProjectUploads(ctx context.Context, address, chainId, projectId string) ([]models.UploadRecord, error) {
+	if uploadRepo == nil {
+		return nil, errors.New("upload repository is not initialized")
+	}
+
+	chain, err := ResolveChain(chainId)
+	if err != nil {
+		return nil, err
+	}
+	pid, err := ParseProjectID(projectId)
+	if err != nil {
+		return nil, err
+	}
+
+	isAuthorized, err := CheckContractAuthorization(ctx, chain, address, Bytes32ToHex(pid))
+	if err != nil {
+		return nil, fmt.Errorf("合约权限检查失败: %w", err)
+	}
+	if !isAuthorized {
+		return nil, fmt.Errorf("%w: %s 不是项目 %s 的所有者或授权提交者", ErrAccessDenied, address, projectId)
+	}
+	return uploadRepo.UploadsByProject(chain.ChainID, Bytes32ToHex(pid))
+}
@@ -0,0 +1,395 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"oracle-backend/internal/config"
+	"oracle-backend/internal/models"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// receiptPollInterval 轮询交易回执的间隔
+	receiptPollInterval = 3 * time.Second
+	// maxBatchRelayEntries 一笔batchSubmitData交易最多包含的提交数
+	maxBatchRelayEntries = 50
+	// relayedTxRetention 交易达到最终状态后仍可查询的时长，之后从内存中移除
+	relayedTxRetention = time.Hour
+)
+
+var (
+	// ErrSubmissionNotFound 后端没有该项目和数据ID的上传记录
+	ErrSubmissionNotFound = errors.New("submission not found")
+	// ErrRelayFailed 中继交易签名或广播失败
+	ErrRelayFailed = errors.New("relay transaction failed")
+)
+
+// Relayer 中继模式：使用后端持有的提交者私钥代为发送submitData/batchSubmitData交易
+// 按链管理nonce，并在后台跟踪交易回执
+type Relayer struct {
+	key            *ecdsa.PrivateKey
+	address        common.Address
+	pool           *ClientPool
+	receiptTimeout time.Duration
+
+	// sendMu 保证同一时间只发送一笔交易，nonces由它保护
+	sendMu sync.Mutex
+	nonces map[uint64]uint64
+
+	// txs 尚在跟踪或刚达到最终状态的交易，最终状态保留relayedTxRetention后移除
+	mu  sync.Mutex
+	txs map[common.Hash]*models.RelayedTx
+}
+
+// NewRelayer 从keystore文件加载提交者私钥并创建Relayer
+func NewRelayer(cfg *config.RelayerConfig, pool *ClientPool) (*Relayer, error) {
+	keyJSON, err := os.ReadFile(cfg.KeystoreFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore file: %w", err)
+	}
+	key, err := keystore.DecryptKey(keyJSON, cfg.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore: %w", err)
+	}
+
+	return &Relayer{
+		key:            key.PrivateKey,
+		address:        key.Address,
+		pool:           pool,
+		receiptTimeout: cfg.ReceiptTimeout,
+		nonces:         make(map[uint64]uint64),
+		txs:            make(map[common.Hash]*models.RelayedTx),
+	}, nil
+}
+
+// Address 返回中继使用的提交者地址
+func (r *Relayer) Address() common.Address {
+	return r.address
+}
+
+// SubmitData 发送submitData交易
+func (r *Relayer) SubmitData(ctx context.Context, chain *config.ChainConfig, entry models.SubmitEntry) (*models.RelayedTx, error) {
+	return r.send(ctx, chain, "submitData", entry.Pid, entry.Did, entry.CoreData, entry.DataHash)
+}
+
+// BatchSubmitData 发送batchSubmitData交易，一次提交多条数据
+func (r *Relayer) BatchSubmitData(ctx context.Context, chain *config.ChainConfig, entries []models.SubmitEntry) (*models.RelayedTx, error) {
+	if len(entries) == 0 {
+		return nil, errors.New("no entries to submit")
+	}
+
+	pids := make([][32]byte, len(entries))
+	dids := make([][32]byte, len(entries))
+	coreDataArray := make([][]byte, len(entries))
+	dataHashes := make([][32]byte, len(entries))
+	for i, entry := range entries {
+		pids[i] = entry.Pid
+		dids[i] = entry.Did
+		coreDataArray[i] = entry.CoreData
+		dataHashes[i] = entry.DataHash
+	}
+
+	return r.send(ctx, chain, "batchSubmitData", pids, dids, coreDataArray, dataHashes)
+}
+
+// send 分配nonce、签名并广播交易，然后在后台跟踪回执
+// 同一时间只发送一笔交易，保证同一条链上的nonce连续
+func (r *Relayer) send(ctx context.Context, chain *config.ChainConfig, method string, args ...interface{}) (*models.RelayedTx, error) {
+	client, err := r.pool.Get(chain.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	r.sendMu.Lock()
+	defer r.sendMu.Unlock()
+
+	nonce, ok := r.nonces[chain.ChainID]
+	if !ok {
+		nonce, err = client.PendingNonce(ctx, r.address)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := client.SignTransaction(ctx, r.key, new(big.Int).SetUint64(chain.ChainID), nonce, method, args...)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		// 发送失败时丢弃缓存的nonce，下次从节点重新获取
+		delete(r.nonces, chain.ChainID)
+		return nil, err
+	}
+	r.nonces[chain.ChainID] = nonce + 1
+
+	relayed := &models.RelayedTx{
+		ChainID:     chain.ChainID,
+		TxHash:      tx.Hash().Hex(),
+		Method:      method,
+		From:        r.address.Hex(),
+		Nonce:       nonce,
+		Status:      models.RelayStatusPending,
+		SubmittedAt: time.Now(),
+	}
+	r.mu.Lock()
+	r.txs[tx.Hash()] = relayed
+	result := *relayed
+	r.mu.Unlock()
+
+	go r.track(chain, client, tx)
+	return &result, nil
+}
+
+// track 轮询交易回执直到达到配置的确认数、交易失败或超时，结束后在保留期满时移除该交易
+func (r *Relayer) track(chain *config.ChainConfig, client *OracleClient, tx *types.Transaction) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.receiptTimeout)
+	defer cancel()
+	defer time.AfterFunc(relayedTxRetention, func() { r.evict(tx.Hash()) })
+
+	ticker := time.NewTicker(receiptPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.update(tx.Hash(), func(t *models.RelayedTx) {
+				if t.Status == models.RelayStatusPending || t.Status == models.RelayStatusMined {
+					t.Status = models.RelayStatusTimeout
+				}
+			})
+			log.Printf("relayer: timed out waiting for receipt of %s on chain %d", tx.Hash().Hex(), chain.ChainID)
+			return
+		case <-ticker.C:
+		}
+
+		receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+		if errors.Is(err, ethereum.NotFound) {
+			continue
+		}
+		if err != nil {
+			log.Printf("relayer: failed to get receipt of %s: %v", tx.Hash().Hex(), err)
+			continue
+		}
+
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			r.update(tx.Hash(), func(t *models.RelayedTx) {
+				t.Status = models.RelayStatusFailed
+				t.BlockNumber = receipt.BlockNumber.Uint64()
+				t.GasUsed = receipt.GasUsed
+				t.Error = "transaction reverted"
+			})
+			return
+		}
+
+		head, err := client.GetLatestBlockNumber(ctx)
+		if err != nil {
+			continue
+		}
+		confirmed := head >= receipt.BlockNumber.Uint64()+chain.Confirmations
+		r.update(tx.Hash(), func(t *models.RelayedTx) {
+			t.Status = models.RelayStatusMined
+			if confirmed {
+				t.Status = models.RelayStatusConfirmed
+			}
+			t.BlockNumber = receipt.BlockNumber.Uint64()
+			t.GasUsed = receipt.GasUsed
+		})
+		if confirmed {
+			return
+		}
+	}
+}
+
+// update 在锁内修改交易状态
+func (r *Relayer) update(txHash common.Hash, fn func(t *models.RelayedTx)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if t, ok := r.txs[txHash]; ok {
+		fn(t)
+	}
+}
+
+// evict 从内存中移除已达到最终状态的交易
+func (r *Relayer) evict(txHash common.Hash) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.txs, txHash)
+}
+
+// Transaction 查询中继交易的当前状态，达到最终状态超过relayedTxRetention的交易不再返回
+func (r *Relayer) Transaction(txHash common.Hash) (*models.RelayedTx, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, ok := r.txs[txHash]
+	if !ok {
+		return nil, false
+	}
+	result := *t
+	return &result, true
+}
+
+// relayer 中继模式实例，未启用时为nil
+var relayer *Relayer
+
+// SetRelayer 设置服务层使用的Relayer
+func SetRelayer(r *Relayer) {
+	relayer = r
+}
+
+// RelayEnabled 是否启用了中继模式
+func RelayEnabled() bool {
+	return relayer != nil
+}
+
+// RelaySubmission 由后端代为调用submitData上链
+// 项目ID、数据日期、核心数据和文件哈希均取自已验证的提交，保证上链内容与用户签名一致
+func RelaySubmission(ctx context.Context, submission *Submission) (*models.RelayedTx, error) {
+	if relayer == nil {
+		return nil, errors.New("中继模式未启用")
+	}
+	sigData := submission.SigData
+
+	dataHash, err := ComputeDataHash(sigData.FileHashes)
+	if err != nil {
+		return nil, err
+	}
+
+	year, month, day, err := ParseDataDate(sigData.DataDate)
+	if err != nil {
+		return nil, err
+	}
+	client, err := ChainClient(submission.Chain)
+	if err != nil {
+		return nil, err
+	}
+	did, err := client.EncodeDid(ctx, year, month, day)
+	if err != nil {
+		return nil, fmt.Errorf("计算数据ID失败: %w", err)
+	}
+
+	return relayer.SubmitData(ctx, submission.Chain, models.SubmitEntry{
+		Pid:      submission.Pid,
+		Did:      did,
+		CoreData: submission.CoreData,
+		DataHash: dataHash,
+	})
+}
+
+// RelayBatch 由后端调用batchSubmitData将同一条链上已上传的多条提交一次上链
+// address须为每个项目的授权提交者；上链内容取自上传时已通过签名（或API密钥）验证并保存的核心数据和文件哈希
+func RelayBatch(ctx context.Context, address string, req *models.BatchRelayRequest) (*models.RelayedTx, error) {
+	if relayer == nil {
+		return nil, errors.New("中继模式未启用")
+	}
+	if uploadRepo == nil {
+		return nil, errors.New("upload repository is not initialized")
+	}
+	if len(req.Submissions) > maxBatchRelayEntries {
+		return nil, fmt.Errorf("一次最多中继 %d 条提交", maxBatchRelayEntries)
+	}
+	chain, err := ResolveChain(req.ChainID)
+	if err != nil {
+		return nil, fmt.Errorf("不支持的链: %w", err)
+	}
+	client, err := ChainClient(chain)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]models.SubmitEntry, 0, len(req.Submissions))
+	seen := make(map[[64]byte]bool)
+	for _, ref := range req.Submissions {
+		pid, err := ParseProjectID(ref.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		did, err := ResolveDid(ctx, client, ref.Did)
+		if err != nil {
+			return nil, fmt.Errorf("invalid data id %q: %w", ref.Did, err)
+		}
+		var key [64]byte
+		copy(key[:32], pid[:])
+		copy(key[32:], did[:])
+		if seen[key] {
+			return nil, fmt.Errorf("重复的提交: %s/%s", ref.ProjectID, ref.Did)
+		}
+		seen[key] = true
+
+		isAuthorized, err := CheckContractAuthorization(ctx, chain, address, Bytes32ToHex(pid))
+		if err != nil {
+			return nil, fmt.Errorf("合约权限检查失败: %w", err)
+		}
+		if !isAuthorized {
+			return nil, fmt.Errorf("%w: %s 不是项目 %s 的所有者或授权提交者", ErrAccessDenied, address, ref.ProjectID)
+		}
+
+		entry, err := storedSubmitEntry(chain.ChainID, pid, did)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+
+	tx, err := relayer.BatchSubmitData(ctx, chain, entries)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRelayFailed, err)
+	}
+	return tx, nil
+}
+
+// storedSubmitEntry 根据已保存的提交记录构造上链数据：dataHash按签名的文件哈希计算，核心数据取自上传记录
+func storedSubmitEntry(chainID uint64, pid, did [32]byte) (*models.SubmitEntry, error) {
+	manifest, err := uploadRepo.Submission(chainID, Bytes32ToHex(pid), Bytes32ToHex(did))
+	if err != nil {
+		return nil, err
+	}
+	uploads, err := uploadRepo.UploadsBySubmission(chainID, Bytes32ToHex(pid), Bytes32ToHex(did))
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil || len(uploads) == 0 {
+		return nil, fmt.Errorf("%w: %s/%s", ErrSubmissionNotFound, Bytes32ToHex(pid), Bytes32ToHex(did))
+	}
+
+	dataHash, err := ComputeDataHash(manifest.FileHashes)
+	if err != nil {
+		return nil, err
+	}
+	coreData, err := hexutil.Decode(uploads[0].CoreData)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stored core data: %w", err)
+	}
+	return &models.SubmitEntry{
+		Pid:      pid,
+		Did:      did,
+		CoreData: coreData,
+		DataHash: dataHash,
+	}, nil
+}
+
+// GetRelayedTransaction 查询中继交易的状态
+func GetRelayedTransaction(txHash string) (*models.RelayedTx, error) {
+	if relayer == nil {
+		return nil, errors.New("中继模式未启用")
+	}
+	tx, ok := relayer.Transaction(common.HexToHash(txHash))
+	if !ok {
+		return nil, fmt.Errorf("transaction not found: %s", txHash)
+	}
+	return tx, nil
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"oracle-backend/internal/config"
+	"oracle-backend/internal/models"
+	"oracle-backend/internal/store"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const (
+	// nonceBits 服务端签发的nonce的随机位数
+	nonceBits = 128
+	// replayPruneInterval 清理过期nonce和签名记录的间隔
+	replayPruneInterval = 10 * time.Minute
+	// maxOutstandingNonces 每个地址同时有效的nonce数量上限，超过时替换最早签发的nonce
+	maxOutstandingNonces = 5
+)
+
+// ErrInvalidAddress 请求nonce的地址格式无效
+var ErrInvalidAddress = errors.New("invalid address")
+
+// replayStore 防重放存储，由main在启动时设置
+var replayStore store.ReplayStore
+
+// SetReplayStore 设置服务层使用的防重放存储
+func SetReplayStore(rs store.ReplayStore) {
+	replayStore = rs
+}
+
+// IssueNonce 为地址签发一次性nonce，上传签名中必须包含该nonce
+// 每个地址最多保留maxOutstandingNonces个未过期的nonce，无需登录即可调用时不会无限写入数据库
+func IssueNonce(address string) (*models.IssuedNonce, error) {
+	if replayStore == nil {
+		return nil, errors.New("replay store is not initialized")
+	}
+	if !common.IsHexAddress(address) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAddress, address)
+	}
+
+	n, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), nonceBits))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	now := time.Now()
+	nonce := &models.IssuedNonce{
+		Address:   common.HexToAddress(address).Hex(),
+		Nonce:     n.String(),
+		IssuedAt:  now,
+		ExpiresAt: now.Add(signatureConfig.NonceTTL),
+	}
+	if err := replayStore.SaveNonce(*nonce, maxOutstandingNonces); err != nil {
+		return nil, fmt.Errorf("failed to save nonce: %w", err)
+	}
+	return nonce, nil
+}
+
+// checkSignatureTimestamp 检查签名时间戳（毫秒）在有效期内，且不超前于服务器时间太多
+func checkSignatureTimestamp(timestamp int64, now time.Time) error {
+	signedAt := time.UnixMilli(timestamp)
+	if now.Sub(signedAt) > signatureConfig.Window {
+		return errors.New("签名已过期")
+	}
+	if signedAt.Sub(now) > signatureConfig.MaxClockSkew {
+		return errors.New("签名时间戳超前于服务器时间")
+	}
+	return nil
+}
+
+// signatureClaim 已通过防重放检查、等待与上传元数据一起记录的签名
+type signatureClaim struct {
+	sig   models.UsedSignature
+	nonce string
+	// keys 处理期间在pendingSignatures中占用的key
+	keys []string
+}
+
+// pendingSignatures 正在处理的提交占用的签名摘要和nonce，防止同一签名的并发提交同时写入存储后端
+var pendingSignatures sync.Map
+
+// claimSignature 检查签名未被使用且nonce有效，并在提交处理期间占用该签名和nonce
+// 此时不消费nonce：签名和nonce在文件全部保存后与上传元数据在同一事务中记录，提交被拒绝时无需重新获取nonce和签名
+// 签名数据必须包含服务端签发的nonce
+func claimSignature(chain *config.ChainConfig, signer string, hash []byte, sigData *models.SignatureData, now time.Time) (*signatureClaim, error) {
+	if replayStore == nil {
+		return nil, errors.New("replay store is not initialized")
+	}
+	if sigData.Nonce == "" {
+		return nil, errors.New("签名数据缺少nonce")
+	}
+
+	claim := &signatureClaim{
+		sig: models.UsedSignature{
+			Hash:    hexutil.Encode(hash),
+			Signer:  signer,
+			ChainID: chain.ChainID,
+			UsedAt:  now,
+			// 超过有效期的签名会被时间戳检查拒绝，之后无需再保留记录
+			ExpiresAt: time.UnixMilli(sigData.Timestamp).Add(signatureConfig.Window + signatureConfig.MaxClockSkew),
+		},
+		nonce: sigData.Nonce,
+	}
+	if err := replayError(replayStore.CheckSignature(claim.sig, claim.nonce)); err != nil {
+		return nil, err
+	}
+
+	for _, key := range []string{"sig/" + claim.sig.Hash, "nonce/" + strings.ToLower(signer) + "/" + claim.nonce} {
+		if _, loaded := pendingSignatures.LoadOrStore(key, struct{}{}); loaded {
+			claim.release()
+			return nil, errors.New("使用该签名或nonce的提交正在处理中")
+		}
+		claim.keys = append(claim.keys, key)
+	}
+	return claim, nil
+}
+
+// release 提交处理结束后释放对签名和nonce的占用
+func (c *signatureClaim) release() {
+	if c == nil {
+		return
+	}
+	for _, key := range c.keys {
+		pendingSignatures.Delete(key)
+	}
+	c.keys = nil
+}
+
+// replayError 将存储层的防重放错误转换为提示
+func replayError(err error) error {
+	switch {
+	case errors.Is(err, store.ErrSignatureUsed):
+		return errors.New("签名已被使用")
+	case errors.Is(err, store.ErrNonceInvalid):
+		return errors.New("nonce无效、已使用或已过期")
+	default:
+		return err
+	}
+}
+
+// StartReplayPruner 在后台定期删除过期的nonce和签名记录
+func StartReplayPruner(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(replayPruneInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if n, err := replayStore.PruneReplayState(time.Now()); err != nil {
+				log.Printf("replay: failed to prune expired entries: %v", err)
+			} else if n > 0 {
+				log.Printf("replay: pruned %d expired entries", n)
+			}
+		}
+	}()
+}
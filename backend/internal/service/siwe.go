@@ -0,0 +1,133 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"oracle-backend/internal/models"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// siweHeaderSuffix EIP-4361消息第一行域名之后的固定内容
+const siweHeaderSuffix = " wants you to sign in with your Ethereum account:"
+
+// ParseSIWEMessage 按EIP-4361解析登录消息：
+//
+//	<domain> wants you to sign in with your Ethereum account:
+//	<address>
+//
+//	[<statement>]
+//
+//	URI: <uri>
+//	Version: 1
+//	Chain ID: <chainId>
+//	Nonce: <nonce>
+//	Issued At: <RFC 3339时间>
+//	[Expiration Time: ...] [Not Before: ...] [Request ID: ...] [Resources: 列表]
+func ParseSIWEMessage(message string) (*models.SIWEMessage, error) {
+	lines := strings.Split(strings.ReplaceAll(message, "\r\n", "\n"), "\n")
+	if len(lines) < 3 {
+		return nil, errors.New("siwe: message too short")
+	}
+
+	msg := &models.SIWEMessage{}
+	domain, ok := strings.CutSuffix(lines[0], siweHeaderSuffix)
+	if !ok || domain == "" {
+		return nil, errors.New("siwe: invalid header line")
+	}
+	// 域名前可以带scheme（如https://example.com），只比较域名部分
+	if _, host, found := strings.Cut(domain, "://"); found {
+		domain = host
+	}
+	msg.Domain = domain
+
+	// 地址必须是EIP-55校验和格式
+	if !common.IsHexAddress(lines[1]) || common.HexToAddress(lines[1]).Hex() != lines[1] {
+		return nil, fmt.Errorf("siwe: address %q is not EIP-55 checksummed", lines[1])
+	}
+	msg.Address = lines[1]
+	if lines[2] != "" {
+		return nil, errors.New("siwe: expected empty line after address")
+	}
+
+	// 可选的statement，前后各一个空行
+	i := 3
+	if i < len(lines) && lines[i] != "" && !strings.HasPrefix(lines[i], "URI: ") {
+		msg.Statement = lines[i]
+		i++
+	}
+	if i >= len(lines) || lines[i] != "" {
+		return nil, errors.New("siwe: expected empty line before fields")
+	}
+	i++
+
+	seen := make(map[string]bool)
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if line == "" && i == len(lines)-1 {
+			break
+		}
+		if line == "Resources:" {
+			for i+1 < len(lines) && strings.HasPrefix(lines[i+1], "- ") {
+				i++
+				msg.Resources = append(msg.Resources, strings.TrimPrefix(lines[i], "- "))
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("siwe: invalid line %q", line)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("siwe: duplicate field %q", key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "URI":
+			msg.URI = value
+		case "Version":
+			msg.Version = value
+		case "Chain ID":
+			msg.ChainID, err = strconv.ParseUint(value, 10, 64)
+		case "Nonce":
+			msg.Nonce = value
+		case "Issued At":
+			msg.IssuedAt, err = time.Parse(time.RFC3339, value)
+		case "Expiration Time":
+			var t time.Time
+			t, err = time.Parse(time.RFC3339, value)
+			msg.ExpirationTime = &t
+		case "Not Before":
+			var t time.Time
+			t, err = time.Parse(time.RFC3339, value)
+			msg.NotBefore = &t
+		case "Request ID":
+			msg.RequestID = value
+		default:
+			return nil, fmt.Errorf("siwe: unknown field %q", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("siwe: invalid %s: %w", key, err)
+		}
+	}
+
+	for _, required := range []string{"URI", "Version", "Chain ID", "Nonce", "Issued At"} {
+		if !seen[required] {
+			return nil, fmt.Errorf("siwe: missing field %q", required)
+		}
+	}
+	if msg.Version != "1" {
+		return nil, fmt.Errorf("siwe: unsupported version %q", msg.Version)
+	}
+	if len(msg.Nonce) < 8 {
+		return nil, errors.New("siwe: nonce must be at least 8 characters")
+	}
+	return msg, nil
+}
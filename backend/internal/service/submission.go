@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"oracle-backend/pkg/dataid"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ComputeDataHash 按前端的规则计算上链的dataHash
+// 单个文件：文件哈希本身（补齐或截断为64位十六进制）
+// 多个文件：对以逗号拼接的文件哈希字符串做keccak256
+func ComputeDataHash(fileHashes []string) ([32]byte, error) {
+	switch len(fileHashes) {
+	case 0:
+		return [32]byte{}, errors.New("no file hashes")
+	case 1:
+		cleanHash := strings.TrimPrefix(fileHashes[0], "0x")
+		if len(cleanHash) < 64 {
+			cleanHash = strings.Repeat("0", 64-len(cleanHash)) + cleanHash
+		}
+		return HexToBytes32(cleanHash[:64])
+	default:
+		return crypto.Keccak256Hash([]byte(strings.Join(fileHashes, ","))), nil
+	}
+}
+
+// ParseDataDate 解析YYYY-MM-DD格式的数据日期
+func ParseDataDate(dataDate string) (year uint16, month, day uint8, err error) {
+	return dataid.ParseDate(dataDate)
+}
+
+// ParseCoreDataForm 解析表单中的coreData字段
+// 前端提交的是序列化后字节的JSON数组（如[1,3,97]），也接受0x开头的十六进制字符串
+func ParseCoreDataForm(coreData string) ([]byte, error) {
+	coreData = strings.TrimSpace(coreData)
+	if strings.HasPrefix(coreData, "0x") {
+		data, err := hex.DecodeString(coreData[2:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid core data hex: %w", err)
+		}
+		return data, nil
+	}
+
+	var values []int
+	if err := json.Unmarshal([]byte(coreData), &values); err != nil {
+		return nil, fmt.Errorf("invalid core data: %w", err)
+	}
+	data := make([]byte, len(values))
+	for i, v := range values {
+		if v < 0 || v > 255 {
+			return nil, fmt.Errorf("invalid core data byte at %d: %d", i, v)
+		}
+		data[i] = byte(v)
+	}
+	return data, nil
+}
+
+// verifyCoreDataHash 检查核心数据的keccak256与签名中的coreDataHash一致
+func verifyCoreDataHash(coreData []byte, coreDataHash string) error {
+	expected := common.HexToHash(coreDataHash)
+	if actual := crypto.Keccak256Hash(coreData); actual != expected {
+		return fmt.Errorf("核心数据哈希与签名数据不一致: %s != %s", actual.Hex(), expected.Hex())
+	}
+	return nil
+}
+
+// ParseProjectID 解析项目ID：0x开头的64位十六进制按bytes32解析，否则按字符串左对齐转换
+func ParseProjectID(projectId string) ([32]byte, error) {
+	if strings.HasPrefix(projectId, "0x") && len(projectId) == 66 {
+		return HexToBytes32(projectId)
+	}
+	if projectId == "" || len(projectId) > 32 {
+		return [32]byte{}, fmt.Errorf("invalid project id: %q", projectId)
+	}
+	return StringToBytes32(projectId), nil
+}
+
+// ResolveDid 解析数据ID：0x开头的按bytes32解析，YYYY-MM-DD格式的日期编码为数据ID
+func ResolveDid(ctx context.Context, client *OracleClient, did string) ([32]byte, error) {
+	if strings.HasPrefix(did, "0x") {
+		return HexToBytes32(did)
+	}
+	year, month, day, err := ParseDataDate(did)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return client.EncodeDid(ctx, year, month, day)
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// gasLimitMarginPercent 在估算的gas基础上增加的余量（百分比）
+const gasLimitMarginPercent = 20
+
+// PendingNonce 获取地址在待处理状态下的nonce
+func (oc *OracleClient) PendingNonce(ctx context.Context, address common.Address) (uint64, error) {
+	var nonce uint64
+	err := oc.withClient(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		var err error
+		nonce, err = client.PendingNonceAt(ctx, address)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get pending nonce: %w", err)
+	}
+	return nonce, nil
+}
+
+// SignTransaction 构造并签名一笔调用合约函数的交易
+// 自动估算gas，节点支持EIP-1559时使用动态手续费交易，否则使用传统交易
+func (oc *OracleClient) SignTransaction(ctx context.Context, key *ecdsa.PrivateKey, chainID *big.Int, nonce uint64,
+	method string, args ...interface{}) (*types.Transaction, error) {
+	callData, err := oc.contractABI.Pack(method, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack call data for %s: %w", method, err)
+	}
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	var (
+		gasLimit uint64
+		header   *types.Header
+		tipCap   *big.Int
+		gasPrice *big.Int
+	)
+	err = oc.withClient(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		var err error
+		gasLimit, err = client.EstimateGas(ctx, ethereum.CallMsg{
+			From: from,
+			To:   &oc.contractAddress,
+			Data: callData,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to estimate gas: %w", err)
+		}
+
+		header, err = client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to get latest header: %w", err)
+		}
+		if header.BaseFee != nil {
+			tipCap, err = client.SuggestGasTipCap(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to suggest gas tip cap: %w", err)
+			}
+			return nil
+		}
+		gasPrice, err = client.SuggestGasPrice(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to suggest gas price: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	gasLimit += gasLimit * gasLimitMarginPercent / 100
+
+	var txData types.TxData
+	if header.BaseFee != nil {
+		// feeCap = 2 * baseFee + tip，保证在基础费用上涨时交易仍可被打包
+		feeCap := new(big.Int).Add(new(big.Int).Mul(header.BaseFee, big.NewInt(2)), tipCap)
+		txData = &types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     nonce,
+			GasTipCap: tipCap,
+			GasFeeCap: feeCap,
+			Gas:       gasLimit,
+			To:        &oc.contractAddress,
+			Data:      callData,
+		}
+	} else {
+		txData = &types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: gasPrice,
+			Gas:      gasLimit,
+			To:       &oc.contractAddress,
+			Data:     callData,
+		}
+	}
+
+	signedTx, err := types.SignNewTx(key, types.LatestSignerForChainID(chainID), txData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	return signedTx, nil
+}
+
+// SendTransaction 广播已签名的交易
+// 重连重试时节点可能已收到该交易，各节点对此返回的错误不同，因此发送失败后向节点查询该交易，节点已有时视为成功
+func (oc *OracleClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	err := oc.withClient(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		return client.SendTransaction(ctx, tx)
+	})
+	if err == nil {
+		return nil
+	}
+	known := oc.withClient(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		_, _, err := client.TransactionByHash(ctx, tx.Hash())
+		return err
+	})
+	if known != nil {
+		return fmt.Errorf("failed to send transaction: %w", err)
+	}
+	return nil
+}
+
+// TransactionReceipt 获取交易回执，交易尚未打包时返回ethereum.NotFound
+func (oc *OracleClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	var receipt *types.Receipt
+	err := oc.withClient(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		var err error
+		receipt, err = client.TransactionReceipt(ctx, txHash)
+		return err
+	})
+	return receipt, err
+}
@@ -1,187 +1,628 @@
 package service
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"mime/multipart"
+	"log"
+	"oracle-backend/internal/config"
 	"oracle-backend/internal/models"
-	"os"
-	"path/filepath"
-	"strings"
+	"oracle-backend/internal/storage"
+	"oracle-backend/internal/store"
+	"slices"
+	"sync"
 	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
-// UploadFile 处理文件上传的业务逻辑
-func UploadFile(file multipart.File, header *multipart.FileHeader, projectId, projectDescription, hashResults,
-	signatureDataStr, signature, chainId string) (*models.FileUploadResult, error) {
+// UploadError 一次提交的上传失败，Files中包含每个文件的处理结果
+type UploadError struct {
+	Message string
+	Files   []models.FileReport
+	// Internal 为true时表示服务端存储失败，而不是提交的数据有误
+	Internal bool
+}
+
+func (e *UploadError) Error() string {
+	return e.Message
+}
+
+// ErrMalformedUpload 上传请求不是按要求编码的multipart表单（表单字段须在文件之前），或读取请求体失败
+var ErrMalformedUpload = errors.New("malformed upload request")
+
+// UploadPart 上传请求中的一个文件，Body直接读取请求体，只能按顺序读取一次
+type UploadPart struct {
+	Filename string
+	// ContentType 客户端在multipart头中声明的类型
+	ContentType string
+	Body        io.Reader
+}
+
+// UploadParts 按请求中的顺序返回上传的文件，没有更多文件时返回io.EOF
+// 读取下一个文件时，上一个文件未读取的内容被丢弃
+type UploadParts interface {
+	Next() (*UploadPart, error)
+}
+
+// stagedUpload 已暂存并通过校验的上传文件
+type stagedUpload struct {
+	filename string
+	staged   *storage.StagedFile
+	key      string
+	// contentType 按文件内容识别的类型
+	contentType *detectedType
+	// created 提交时新建了对象（在blobLocks的保护下确认提交前不存在同一key），回滚时需要删除
+	created bool
+}
+
+// UploadSubmission 处理一次签名提交的所有文件，全部成功或全部不保存，返回保存的文件和已验证的提交
+// 先验证签名，再从请求体中按项目的上传策略逐个读取文件并流式暂存，与签名的FileHashes比对，全部通过后才一起提交到存储后端并记录元数据；
+// 任一步骤失败时删除已暂存和已提交的文件，并通过UploadError返回每个文件的处理结果
+func UploadSubmission(ctx context.Context, parts UploadParts, projectId, dataDate, coreData, hashResults,
+	signatureType, signatureDataStr, signature, signerAddress, chainId string) ([]*models.FileUploadResult, *Submission, error) {
 	// 1. 验证签名
 	if signatureDataStr == "" || signature == "" {
-		return nil, fmt.Errorf("签名数据不完整")
-	}
-
-	// 解析签名数据
-	var sigData models.SignatureData
-	if err := json.Unmarshal([]byte(signatureDataStr), &sigData); err != nil {
-		return nil, fmt.Errorf("签名数据解析失败: %w", err)
+		return nil, nil, fmt.Errorf("签名数据不完整")
 	}
 
-	// 验证签名并恢复地址
-	fileHashesJSON, err := json.Marshal(sigData.FileHashes)
+	// 解析链ID，未配置的链直接拒绝
+	chain, err := ResolveChain(chainId)
 	if err != nil {
-		return nil, fmt.Errorf("文件哈希数组序列化失败: %w", err)
+		return nil, nil, fmt.Errorf("不支持的链: %w", err)
 	}
-	recoveredAddress, err := VerifySignatureWithParams(
-		sigData.ProjectID,
-		sigData.DataDate,
-		sigData.CoreDataHash,
-		string(fileHashesJSON),
-		sigData.Timestamp,
-		signature,
-	)
 
+	// 验证签名、合约权限、时间戳、项目ID、数据日期和核心数据
+	submission, err := verifySubmission(ctx, chain, projectId, dataDate, coreData, signatureType, signatureDataStr, signature, signerAddress)
 	if err != nil {
-		return nil, fmt.Errorf("签名验证失败: %w", err)
+		return nil, nil, err
 	}
+	return storeSubmission(ctx, parts, hashResults, submission, signatureDataStr)
+}
 
-	// 使用从签名中恢复的地址检查合约权限
-	isAuthorized, err := CheckContractAuthorization(recoveredAddress, projectId)
+// UploadKeySubmission 处理一次使用API密钥认证的提交，不需要钱包签名
+// hashResults中的文件哈希按顺序作为本次提交的FileHashes（即链上dataHash的输入），之后与签名提交一样全部成功或全部不保存
+func UploadKeySubmission(ctx context.Context, parts UploadParts, apiKey, projectId, dataDate, coreData, hashResults,
+	chainId string) ([]*models.FileUploadResult, *Submission, error) {
+	chain, err := ResolveChain(chainId)
 	if err != nil {
-		return nil, fmt.Errorf("合约权限检查失败: %w", err)
-	}
-	if !isAuthorized {
-		return nil, fmt.Errorf("地址未授权: %s 不是项目 %s 的所有者或授权提交者", recoveredAddress, projectId)
+		return nil, nil, fmt.Errorf("不支持的链: %w", err)
 	}
 
-	// 2. 检查签名时间戳（防止重放攻击）
-	now := time.Now().UnixNano() / int64(time.Millisecond)
-	if now-sigData.Timestamp > 300000 { // 5分钟有效期
-		return nil, fmt.Errorf("签名已过期")
+	// 验证API密钥、绑定地址的合约权限、数据日期和核心数据
+	submission, err := verifyKeySubmission(ctx, chain, apiKey, projectId, dataDate, coreData, hashResults)
+	if err != nil {
+		return nil, nil, err
 	}
+	return storeSubmission(ctx, parts, hashResults, submission, "")
+}
 
-	// 3. 验证项目ID和数据日期与签名数据一致
-	if sigData.ProjectID != projectId {
-		return nil, fmt.Errorf("项目ID与签名数据不一致")
-	}
+// storeSubmission 暂存并校验已验证提交的所有文件，全部通过后一起提交到存储后端并记录元数据
+// signedPayload为签名的原始数据，使用API密钥的提交为空
+func storeSubmission(ctx context.Context, parts UploadParts, hashResults string, submission *Submission,
+	signedPayload string) ([]*models.FileUploadResult, *Submission, error) {
+	chain, pid, sigData := submission.Chain, submission.Pid, submission.SigData
+	defer submission.replay.release()
 
-	// 4. 验证文件哈希与签名数据一致
+	// 2. 验证前端传递的文件哈希与签名数据一致
+	frontEndHashes := make(map[string]string)
 	var frontEndHashResults []models.HashResult
 	if err := json.Unmarshal([]byte(hashResults), &frontEndHashResults); err == nil {
 		// 验证文件数量一致
 		if len(sigData.FileHashes) != len(frontEndHashResults) {
-			return nil, fmt.Errorf("文件哈希数量与签名数据不一致")
+			return nil, nil, fmt.Errorf("文件哈希数量与签名数据不一致")
 		}
 
 		// 验证每个文件的哈希值
 		for i, result := range frontEndHashResults {
-			cleanHash := result.HashValue
-			if strings.HasPrefix(cleanHash, "0x") {
-				cleanHash = cleanHash[2:]
+			cleanHash := normalizeHash(result.HashValue)
+			if normalizeHash(sigData.FileHashes[i]) != cleanHash {
+				return nil, nil, fmt.Errorf("文件哈希与签名数据不一致: %s", result.FileName)
 			}
+			frontEndHashes[result.FileName] = cleanHash
+		}
+	}
+
+	if blobStore == nil {
+		return nil, nil, fmt.Errorf("storage backend is not initialized")
+	}
+
+	// 读取文件前检查数据记录是否已有不能替换的提交，保存元数据时会在同一事务中再次检查
+	if _, _, err := checkResubmission(ctx, submission); err != nil {
+		return nil, nil, err
+	}
+
+	// 3. 签名通过后才读取文件，读取时检查项目的上传策略（文件数、大小和按内容识别的类型）
+	policy, err := uploadPolicy(chain.ChainID, Bytes32ToHex(pid))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 4. 暂存并校验每个文件
+	uploads, reports, err := stageUploads(parts, policy, sigData.FileHashes, frontEndHashes)
+	defer func() {
+		for _, u := range uploads {
+			u.staged.Remove()
+		}
+	}()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 5. 全部文件通过校验后一起提交，失败时回滚已提交的文件（此时uploads与reports一一对应）
+	// 对象key只由链ID、bytes32项目ID和服务端计算的哈希构成，不使用客户端提交的项目ID和文件名
+	keys := make([]string, len(uploads))
+	for i, u := range uploads {
+		key, err := storage.Key(chain.ChainID, pid, u.staged.Hash)
+		if err != nil {
+			reports[i].Status = models.FileStatusFailed
+			reports[i].Error = err.Error()
+			return nil, nil, &UploadError{Message: "文件保存失败，本次提交的文件均未保存", Files: reports, Internal: true}
+		}
+		u.key, keys[i] = key, key
+	}
+
+	// 提交、记录元数据和回滚期间占用这些key，同一文件的并发提交依次判断对象是否由自己新建，
+	// 回滚时不会删除另一个提交已经引用的对象
+	unlock := blobLocks.lock(keys)
+	defer unlock()
+
+	for i, u := range uploads {
+		_, err := blobStore.Stat(ctx, u.key)
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			u.created = true
+		case err != nil:
+			reports[i].Status = models.FileStatusFailed
+			reports[i].Error = fmt.Sprintf("failed to save file: %v", err)
+			rollbackUploads(ctx, uploads[:i], reports)
+			return nil, nil, &UploadError{Message: "文件保存失败，本次提交的文件均未保存", Files: reports, Internal: true}
+		}
+
+		if err := storage.Commit(ctx, blobStore, u.key, u.staged, u.contentType.contentType); err != nil {
+			reports[i].Status = models.FileStatusFailed
+			reports[i].Error = fmt.Sprintf("failed to save file: %v", err)
+			rollbackUploads(ctx, uploads[:i], reports)
+			return nil, nil, &UploadError{Message: "文件保存失败，本次提交的文件均未保存", Files: reports, Internal: true}
+		}
+		reports[i].Status = models.FileStatusCommitted
+	}
 
-			if i < len(sigData.FileHashes) && sigData.FileHashes[i] != cleanHash {
-				return nil, fmt.Errorf("文件哈希与签名数据不一致: %s", result.FileName)
+	uploadTime := time.Now()
+	results := make([]*models.FileUploadResult, len(uploads))
+	for i, u := range uploads {
+		results[i] = &models.FileUploadResult{
+			FileName:            u.filename,
+			FileSize:            u.staged.Size,
+			FileHash:            u.staged.Hash,
+			FilePath:            u.key,
+			UploadTime:          uploadTime,
+			ContentType:         u.contentType.contentType,
+			SniffedContentType:  u.contentType.sniffed,
+			DeclaredContentType: u.contentType.declared,
+			Signer:              submission.Signer,
+			Signature:           submission.Signature,
+		}
+	}
+
+	// 6. 持久化本次提交的元数据，同时用于之后与链上dataHash对账
+	// 签名和nonce与元数据在同一事务中记录为已使用，此前任一步骤失败时都可以用同一签名重新提交
+	if err := RecordSubmission(ctx, submission, signedPayload, results); err != nil {
+		rollbackUploads(ctx, uploads, reports)
+		if errors.Is(err, store.ErrSignatureUsed) || errors.Is(err, store.ErrNonceInvalid) {
+			return nil, nil, &UploadError{Message: replayError(err).Error() + "，本次提交的文件均未保存", Files: reports}
+		}
+		if errors.Is(err, ErrSubmissionExists) {
+			return nil, nil, &UploadError{Message: err.Error() + "，本次提交的文件均未保存", Files: reports}
+		}
+		return nil, nil, &UploadError{Message: fmt.Sprintf("保存上传记录失败: %v", err), Files: reports, Internal: true}
+	}
+
+	return results, submission, nil
+}
+
+// stageUploads 按顺序读取每个文件，识别类型后流式写入临时文件并计算哈希，检查文件与签名的FileHashes一一对应
+// 文件数、单个文件大小和总大小在读取时按项目的上传策略检查，超过时立即停止读取请求体；
+// 返回已暂存的文件（调用方负责删除临时文件），以及全部通过时每个文件的报告，否则通过UploadError返回
+func stageUploads(parts UploadParts, policy *models.UploadPolicy, fileHashes []string, frontEndHashes map[string]string) ([]*stagedUpload, []models.FileReport, error) {
+	// 签名中每个哈希可以被一个上传文件认领
+	remaining := make(map[string]int)
+	for _, h := range fileHashes {
+		remaining[normalizeHash(h)]++
+	}
+
+	var uploads []*stagedUpload
+	var reports []models.FileReport
+	var total int64
+	ok := true
+	for {
+		part, err := parts.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return uploads, nil, fmt.Errorf("%w: %w", ErrMalformedUpload, err)
+		}
+		if len(reports) == policy.MaxFiles {
+			return uploads, nil, &UploadError{Message: fmt.Sprintf("文件数超过项目允许的 %d 个，本次提交的文件均未保存", policy.MaxFiles)}
+		}
+		reports = append(reports, models.FileReport{FileName: part.Filename, Status: models.FileStatusAborted})
+		report := &reports[len(reports)-1]
+
+		// 单个文件最多读取项目允许的大小，且不超过剩余的总大小
+		limit := min(policy.MaxFileSize, policy.MaxTotalSize-total)
+		staged, dt, err := stageUpload(part, policy, limit)
+		var policyErr *policyViolation
+		var bodyErr *requestBodyError
+		switch {
+		case errors.As(err, &bodyErr):
+			return uploads, nil, fmt.Errorf("%w: 读取文件 %s 失败: %w", ErrMalformedUpload, part.Filename, bodyErr.err)
+		case errors.As(err, &policyErr) && policyErr.tooLarge:
+			report.Status = models.FileStatusFailed
+			if limit < policy.MaxFileSize {
+				report.Error = fmt.Sprintf("文件总大小超过项目允许的 %d 字节", policy.MaxTotalSize)
+			} else {
+				report.Error = fmt.Sprintf("文件大小超过项目允许的 %d 字节", policy.MaxFileSize)
 			}
+			return uploads, nil, &UploadError{Message: "文件不符合项目的上传策略，本次提交的文件均未保存", Files: reports}
+		case err != nil:
+			// 类型不符合策略时跳过该文件的其余内容，继续检查其他文件
+			report.Status = models.FileStatusFailed
+			report.Error = err.Error()
+			ok = false
+			continue
+		}
+		total += staged.Size
+		uploads = append(uploads, &stagedUpload{filename: part.Filename, staged: staged, contentType: dt})
+		report.FileHash = staged.Hash
+
+		// 前端传递了该文件的哈希值时，检查与后端计算的一致
+		if frontEndHash, found := frontEndHashes[part.Filename]; found && frontEndHash != staged.Hash {
+			report.Status = models.FileStatusFailed
+			report.Error = fmt.Sprintf("文件哈希不匹配 (前端: %s, 后端: %s)", frontEndHash, staged.Hash)
+			ok = false
+			continue
+		}
+		// 文件内容必须是签名数据中尚未被认领的文件之一
+		if remaining[staged.Hash] == 0 {
+			report.Status = models.FileStatusFailed
+			report.Error = "文件哈希不在签名数据中或重复上传"
+			ok = false
+			continue
+		}
+		remaining[staged.Hash]--
+	}
+	if len(reports) == 0 {
+		return uploads, nil, &UploadError{Message: "没有上传文件"}
+	}
+
+	for _, h := range fileHashes {
+		if h = normalizeHash(h); remaining[h] > 0 {
+			remaining[h]--
+			reports = append(reports, models.FileReport{
+				FileHash: h,
+				Status:   models.FileStatusMissing,
+				Error:    "签名数据中的文件未上传",
+			})
+			ok = false
 		}
 	}
+	if !ok {
+		return uploads, nil, &UploadError{Message: "文件校验失败，本次提交的文件均未保存", Files: reports}
+	}
+	return uploads, reports, nil
+}
+
+// policyViolation 文件不符合项目的上传策略
+type policyViolation struct {
+	message string
+	// tooLarge 文件超过大小限制，此时已停止读取该文件
+	tooLarge bool
+}
+
+func (e *policyViolation) Error() string {
+	return e.message
+}
+
+// requestBodyError 读取请求体失败（如超过请求大小上限或客户端断开），而不是暂存文件失败
+type requestBodyError struct {
+	err error
+}
 
-	// 5. 继续原有的文件处理逻辑
-	fileContent, err := io.ReadAll(file)
+func (e *requestBodyError) Error() string {
+	return e.err.Error()
+}
+
+// bodyReader 记录读取请求体时的错误，用于区分请求体的错误和写入临时文件的错误
+type bodyReader struct {
+	r   io.Reader
+	err error
+}
+
+func (b *bodyReader) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if err != nil && err != io.EOF {
+		b.err = err
+	}
+	return n, err
+}
+
+// stageUpload 读取文件开头的字节识别类型，符合项目的上传策略时将文件写入临时文件，最多读取limit字节
+func stageUpload(part *UploadPart, policy *models.UploadPolicy, limit int64) (*storage.StagedFile, *detectedType, error) {
+	body := &bodyReader{r: part.Body}
+
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(body, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, nil, &requestBodyError{err: err}
+	}
+	head = head[:n]
+	if int64(n) > limit {
+		return nil, nil, &policyViolation{message: "file too large", tooLarge: true}
+	}
+	dt, err := checkFileType(policy, head, part.ContentType)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
+		return nil, nil, &policyViolation{message: err.Error()}
 	}
 
-	// 重置文件指针
-	if _, err := file.Seek(0, 0); err != nil {
-		return nil, fmt.Errorf("failed to reset file pointer: %w", err)
+	// 多读取一个字节，用于判断文件是否超过limit
+	staged, err := storage.StageFile(config.StagingDir(), io.MultiReader(bytes.NewReader(head), io.LimitReader(body, limit-int64(n)+1)))
+	if err != nil {
+		if body.err != nil {
+			return nil, nil, &requestBodyError{err: body.err}
+		}
+		return nil, nil, err
+	}
+	if staged.Size > limit {
+		staged.Remove()
+		return nil, nil, &policyViolation{message: "file too large", tooLarge: true}
 	}
+	return staged, dt, nil
+}
 
-	// 计算文件哈希
-	hash := sha256.New()
-	if _, err := hash.Write(fileContent); err != nil {
-		return nil, fmt.Errorf("failed to calculate hash: %w", err)
+// rollbackUploads 删除本次提交新建的对象，并将已提交的文件标记为已回滚
+func rollbackUploads(ctx context.Context, uploads []*stagedUpload, reports []models.FileReport) {
+	for i, u := range uploads {
+		if reports[i].Status != models.FileStatusCommitted {
+			continue
+		}
+		reports[i].Status = models.FileStatusAborted
+		if !u.created {
+			continue
+		}
+		if err := blobStore.Delete(ctx, u.key); err != nil {
+			log.Printf("upload: failed to roll back %s: %v", u.key, err)
+			reports[i].Error = fmt.Sprintf("rollback failed: %v", err)
+		}
 	}
-	hashInBytes := hash.Sum(nil)
-	backendFileHash := hex.EncodeToString(hashInBytes)
+}
 
-	// 使用后端计算的哈希值作为默认值
-	fileHash := backendFileHash
+// blobStore 上传文件的存储后端，由main在启动时设置
+var blobStore storage.BlobStore
 
-	// 尝试使用前端传递的哈希值
-	if hashResults != "" {
-		var frontEndHashResults []models.HashResult
-		if err := json.Unmarshal([]byte(hashResults), &frontEndHashResults); err == nil {
-			for _, result := range frontEndHashResults {
-				if result.FileName == header.Filename {
-					fileHash = result.HashValue
-					if len(fileHash) == 66 && fileHash[:2] == "0x" {
-						fileHash = fileHash[2:]
-					}
+// SetBlobStore 设置服务层使用的存储后端
+func SetBlobStore(bs storage.BlobStore) {
+	blobStore = bs
+}
 
-					// 验证哈希是否匹配
-					if fileHash != backendFileHash {
-						return nil, fmt.Errorf("文件哈希不匹配: %s (前端: %s, 后端: %s)",
-							header.Filename, fileHash, backendFileHash)
-					}
-					break
-				}
+// blobLocks 按对象key串行化文件的提交和回滚
+var blobLocks = &keyedMutex{locks: make(map[string]*keyedLock)}
+
+// keyedMutex 按key加锁，key不再被占用时释放对应的锁
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock 按排序后的顺序占用所有key（重复的key只占用一次），返回释放全部key的函数
+func (m *keyedMutex) lock(keys []string) func() {
+	keys = slices.Compact(slices.Sorted(slices.Values(keys)))
+	held := make([]*keyedLock, len(keys))
+	for i, key := range keys {
+		m.mu.Lock()
+		l := m.locks[key]
+		if l == nil {
+			l = &keyedLock{}
+			m.locks[key] = l
+		}
+		l.refs++
+		m.mu.Unlock()
+
+		l.Lock()
+		held[i] = l
+	}
+
+	return func() {
+		for i, key := range keys {
+			held[i].Unlock()
+			m.mu.Lock()
+			if held[i].refs--; held[i].refs == 0 {
+				delete(m.locks, key)
 			}
+			m.mu.Unlock()
 		}
 	}
+}
 
-	// 6. 保存文件（只使用项目ID作为文件夹名称）
-	projectDirName := projectId
+// Submission 已通过签名（或API密钥）、合约权限和防重放检查的一次提交
+type Submission struct {
+	Chain     *config.ChainConfig
+	ProjectID string
+	// Pid 按ParseProjectID解析的bytes32项目ID，授权检查、存储key、元数据和中继上链都使用该值
+	Pid     [32]byte
+	SigData *models.SignatureData
+	// Signer 从签名中恢复或经ERC-1271验证的提交者地址
+	Signer    string
+	Signature string
+	// CoreData 与签名的coreDataHash一致的序列化核心数据
+	CoreData []byte
+	// CoreDataValues 解码后的核心数据键值对，值为uint256的十进制字符串
+	CoreDataValues map[string]string
+	// APIKeyID 使用API密钥认证时的密钥ID，此时SigData由服务端根据表单生成，Signature为空
+	APIKeyID string
+
+	// replay 签名提交占用的签名和nonce，保存元数据时记录为已使用；使用API密钥的提交为nil
+	replay *signatureClaim
+}
 
-	// 获取当前工作目录（程序运行所在目录）
-	cwd, err := os.Getwd()
+// verifySubmission 验证一次提交的签名数据
+// 解析签名数据、检查签名绑定的链、恢复签名地址、在指定链上检查合约权限，检查时间戳、项目ID、数据日期和核心数据，最后占用签名和nonce
+// signerAddress非空时按声明的签名者验证，合约钱包通过ERC-1271验证
+func verifySubmission(ctx context.Context, chain *config.ChainConfig, projectId, dataDate, coreDataStr, signatureType, signatureDataStr,
+	signature, signerAddress string) (*Submission, error) {
+	pid, err := ParseProjectID(projectId)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get current working directory: %w", err)
+		return nil, err
 	}
 
-	// 构建上传目录路径：直接在程序运行目录下的uploads目录
-	// 构建上传目录路径，添加链ID子目录
-	// 如果chainId为空，使用"default"作为默认值
-	chainDirName := chainId
-	if chainDirName == "" {
-		chainDirName = "default"
+	// 解析签名数据
+	var sigData models.SignatureData
+	if err := json.Unmarshal([]byte(signatureDataStr), &sigData); err != nil {
+		return nil, fmt.Errorf("签名数据解析失败: %w", err)
 	}
-	uploadDir := filepath.Join(cwd, "uploads", chainDirName, projectDirName)
-	fmt.Printf("Upload directory: %s\n", uploadDir)
-	if _, err := os.Stat(uploadDir); os.IsNotExist(err) {
-		if err := os.MkdirAll(uploadDir, 0755); err != nil {
-			return nil, fmt.Errorf("failed to create project directory: %w", err)
-		}
+
+	// 默认只接受EIP-712签名，personal_sign须在配置中启用，旧版前端的请求给出明确的提示
+	if err := checkSignatureType(signatureType, &sigData); err != nil {
+		return nil, err
 	}
 
-	// 保留原始文件扩展名，使用哈希值作为文件名
-	extension := filepath.Ext(header.Filename)
-	uniqueFileName := fmt.Sprintf("%s%s", fileHash, extension)
-	filePath := filepath.Join(uploadDir, uniqueFileName)
-	fmt.Printf("File path: %s\n", filePath)
+	// 签名数据中的链ID必须与提交的链一致，且授权检查使用的节点确实在该链上
+	if err := checkSignatureChain(ctx, chain, &sigData); err != nil {
+		return nil, err
+	}
 
-	dst, err := os.Create(filePath)
+	// 验证签名并恢复地址
+	recoveredAddress, signedHash, err := verifyUploadSignature(ctx, chain, signatureType, signatureDataStr, &sigData, signature, signerAddress)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create destination file: %w", err)
+		return nil, fmt.Errorf("签名验证失败: %w", err)
 	}
-	defer dst.Close()
 
-	if _, err := dst.Write(fileContent); err != nil {
-		return nil, fmt.Errorf("failed to save file: %w", err)
+	// 使用从签名中恢复的地址，在客户端声明的链上检查合约权限
+	isAuthorized, err := CheckContractAuthorization(ctx, chain, recoveredAddress, Bytes32ToHex(pid))
+	if err != nil {
+		return nil, fmt.Errorf("合约权限检查失败: %w", err)
+	}
+	if !isAuthorized {
+		return nil, fmt.Errorf("地址未授权: %s 不是项目 %s 的所有者或授权提交者", recoveredAddress, projectId)
+	}
+
+	// 检查签名时间戳
+	now := time.Now()
+	if err := checkSignatureTimestamp(sigData.Timestamp, now); err != nil {
+		return nil, err
+	}
+
+	// 验证项目ID和数据日期与签名数据一致
+	if sigData.ProjectID != projectId {
+		return nil, fmt.Errorf("项目ID与签名数据不一致")
+	}
+	if _, _, _, err := ParseDataDate(sigData.DataDate); err != nil {
+		return nil, fmt.Errorf("签名数据中的数据日期无效: %w", err)
+	}
+	if dataDate != sigData.DataDate {
+		return nil, fmt.Errorf("数据日期与签名数据不一致: %q != %q", dataDate, sigData.DataDate)
+	}
+
+	// 核心数据必须与签名的coreDataHash一致，并能按前端的序列化格式解码
+	coreData, coreDataValues, err := verifyCoreData(coreDataStr, sigData.CoreDataHash)
+	if err != nil {
+		return nil, err
+	}
+
+	// 所有检查通过后占用签名和nonce，文件全部保存后才与元数据一起记录为已使用（防止重放攻击）
+	claim, err := claimSignature(chain, recoveredAddress, signedHash, &sigData, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Submission{
+		Chain:          chain,
+		ProjectID:      projectId,
+		Pid:            pid,
+		SigData:        &sigData,
+		Signer:         recoveredAddress,
+		Signature:      signature,
+		CoreData:       coreData,
+		CoreDataValues: coreDataValues,
+		replay:         claim,
+	}, nil
+}
+
+// verifyKeySubmission 验证一次使用API密钥的提交，并根据表单生成与签名提交相同结构的SignatureData
+// 密钥须覆盖提交的链和项目，绑定地址当前仍须是项目的授权提交者；hashResults必须列出本次提交的全部文件
+func verifyKeySubmission(ctx context.Context, chain *config.ChainConfig, apiKey, projectId, dataDate, coreDataStr,
+	hashResults string) (*Submission, error) {
+	pid, err := ParseProjectID(projectId)
+	if err != nil {
+		return nil, err
+	}
+	key, err := authenticateAPIKey(ctx, apiKey, chain, Bytes32ToHex(pid))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, _, _, err := ParseDataDate(dataDate); err != nil {
+		return nil, fmt.Errorf("数据日期无效: %w", err)
+	}
+
+	// 没有签名约束文件顺序，链上dataHash按hashResults中的顺序计算
+	var frontEndHashResults []models.HashResult
+	if err := json.Unmarshal([]byte(hashResults), &frontEndHashResults); err != nil {
+		return nil, fmt.Errorf("文件哈希列表解析失败: %w", err)
+	}
+	if len(frontEndHashResults) == 0 {
+		return nil, errors.New("缺少文件哈希列表")
+	}
+	fileHashes := make([]string, len(frontEndHashResults))
+	for i, result := range frontEndHashResults {
+		fileHashes[i] = normalizeHash(result.HashValue)
+	}
+
+	coreData, coreDataValues, err := decodeCoreData(coreDataStr)
+	if err != nil {
+		return nil, err
 	}
 
-	// 7. 返回结果
-	result := &models.FileUploadResult{
-		FileName:    header.Filename,
-		FileSize:    header.Size,
-		FileHash:    fileHash,
-		FilePath:    filePath,
-		UploadTime:  time.Now(),
-		ContentType: header.Header.Get("Content-Type"),
-		Signer:      recoveredAddress,
-		Signature:   signature,
+	return &Submission{
+		Chain:     chain,
+		ProjectID: projectId,
+		Pid:       pid,
+		SigData: &models.SignatureData{
+			ProjectID:    projectId,
+			DataDate:     dataDate,
+			CoreDataHash: crypto.Keccak256Hash(coreData).Hex(),
+			FileHashes:   fileHashes,
+			ChainID:      chain.ChainID,
+			Timestamp:    time.Now().UnixMilli(),
+		},
+		Signer:         key.Address,
+		CoreData:       coreData,
+		CoreDataValues: coreDataValues,
+		APIKeyID:       key.ID,
+	}, nil
+}
+
+// checkSignatureChain 检查签名数据绑定的链ID与表单声明的链一致，并确认该链的客户端连接的节点也在这条链上
+// 任何签名方式都必须在签名数据中包含chainId，否则为一条链签的名可以被提交到另一条链
+func checkSignatureChain(ctx context.Context, chain *config.ChainConfig, sigData *models.SignatureData) error {
+	if sigData.ChainID == 0 {
+		return errors.New("签名数据缺少chainId")
+	}
+	if sigData.ChainID != chain.ChainID {
+		return fmt.Errorf("签名数据中的链ID %d 与提交的链 %d 不一致", sigData.ChainID, chain.ChainID)
 	}
 
-	return result, nil
+	client, err := ChainClient(chain)
+	if err != nil {
+		return fmt.Errorf("获取合约客户端失败: %w", err)
+	}
+	return client.CheckChainID(ctx, chain.ChainID)
 }
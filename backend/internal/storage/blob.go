@@ -0,0 +1,85 @@
+// Package storage 上传文件的存储后端
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"oracle-backend/internal/config"
+)
+
+// ErrNotFound 指定key的对象不存在
+var ErrNotFound = errors.New("blob not found")
+
+// BlobInfo 对象的元数据
+type BlobInfo struct {
+	// Key 对象key，格式为 <chainId>/<pid>/<fileHash>（早期上传的文件为 <chainId>/<projectId>/<fileHash><后缀>）
+	Key         string    `json:"key"`
+	Size        int64     `json:"size"`
+	ContentType string    `json:"contentType,omitempty"`
+	ModTime     time.Time `json:"modTime"`
+}
+
+// BlobStore 上传文件的存储接口，对象以包含文件哈希的key寻址
+type BlobStore interface {
+	// Put 写入对象，size未知时传-1；同一key已存在时覆盖
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get 读取对象，调用方负责关闭返回的Reader；不存在时返回ErrNotFound
+	Get(ctx context.Context, key string) (io.ReadCloser, *BlobInfo, error)
+	// Stat 获取对象的元数据，不存在时返回ErrNotFound
+	Stat(ctx context.Context, key string) (*BlobInfo, error)
+	// Delete 删除对象，不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+	// List 列出指定前缀下的所有对象
+	List(ctx context.Context, prefix string) ([]BlobInfo, error)
+}
+
+// New 按配置创建存储后端
+func New(cfg *config.BlobStoreConfig) (BlobStore, error) {
+	switch cfg.Backend {
+	case config.StorageBackendLocal:
+		return NewLocalStore(cfg.LocalDir)
+	case config.StorageBackendS3:
+		return NewS3Store(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unsupported storage backend: %s", cfg.Backend)
+	}
+}
+
+// fileHashLength 对象key中文件哈希（SHA-256）的十六进制长度
+const fileHashLength = 64
+
+// Key 按规范的上传目录结构构造对象key：<chainId>/<pid>/<fileHash>
+// chainId为十进制整数，pid为0x开头的bytes32十六进制，fileHash为服务端计算的SHA-256（64位小写十六进制），
+// key中不包含客户端提交的项目ID、文件名或后缀
+func Key(chainID uint64, pid [32]byte, fileHash string) (string, error) {
+	if chainID == 0 {
+		return "", errors.New("invalid blob key: chain id is zero")
+	}
+	if len(fileHash) != fileHashLength || strings.Trim(fileHash, "0123456789abcdef") != "" {
+		return "", fmt.Errorf("invalid blob key: file hash %q is not a lowercase sha256 hex", fileHash)
+	}
+	key := fmt.Sprintf("%d/0x%x/%s", chainID, pid, fileHash)
+	return key, validateKey(key)
+}
+
+// validateKey 检查对象key是相对路径且不包含..等可以逃逸出存储根目录的片段
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") || strings.ContainsRune(key, 0) {
+		return fmt.Errorf("invalid blob key: %q", key)
+	}
+	if path.Clean(key) != key {
+		return fmt.Errorf("invalid blob key: %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "." || part == ".." {
+			return fmt.Errorf("invalid blob key: %q", key)
+		}
+	}
+	return nil
+}
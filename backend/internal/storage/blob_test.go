@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKey(t *testing.T) {
+	hash := strings.Repeat("ab", 32)
+	pid := [32]byte{0xAB, 0x01}
+
+	tests := []struct {
+		name     string
+		chainID  uint64
+		fileHash string
+		want     string
+		wantErr  bool
+	}{
+		{name: "canonical", chainID: 1, fileHash: hash, want: "1/0xab01000000000000000000000000000000000000000000000000000000000000/" + hash},
+		{name: "large chain id", chainID: 11155111, fileHash: hash, want: "11155111/0xab01000000000000000000000000000000000000000000000000000000000000/" + hash},
+		{name: "zero chain id", chainID: 0, fileHash: hash, wantErr: true},
+		{name: "uppercase hash", chainID: 1, fileHash: strings.ToUpper(hash), wantErr: true},
+		{name: "0x prefixed hash", chainID: 1, fileHash: "0x" + hash[2:], wantErr: true},
+		{name: "short hash", chainID: 1, fileHash: hash[:63], wantErr: true},
+		{name: "hash with extension", chainID: 1, fileHash: hash + ".csv", wantErr: true},
+		{name: "traversal as hash", chainID: 1, fileHash: "../../" + hash[6:], wantErr: true},
+		{name: "separator in hash", chainID: 1, fileHash: hash[:32] + "/" + hash[33:], wantErr: true},
+		{name: "empty hash", chainID: 1, fileHash: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Key(tt.chainID, pid, tt.fileHash)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Key(%d, %q) = %q, want error", tt.chainID, tt.fileHash, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Key(%d, %q) error: %v", tt.chainID, tt.fileHash, err)
+			}
+			if got != tt.want {
+				t.Fatalf("Key(%d, %q) = %q, want %q", tt.chainID, tt.fileHash, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateKey(t *testing.T) {
+	hash := strings.Repeat("0f", 32)
+	pid := "0x" + strings.Repeat("11", 32)
+
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{name: "canonical", key: "1/" + pid + "/" + hash},
+		// 早期上传的文件使用客户端提交的项目ID和带后缀的文件名，仍须可以读取
+		{name: "legacy project id and extension", key: "1/my-project/" + hash + ".csv"},
+		// validateKey只检查路径安全，规范的链ID和pid由Key保证
+		{name: "non-canonical chain and pid", key: "01/0XAB/" + hash},
+		{name: "empty", key: "", wantErr: true},
+		{name: "parent segment", key: "1/../" + hash, wantErr: true},
+		{name: "leading parent segment", key: "../1/" + pid + "/" + hash, wantErr: true},
+		{name: "only parent", key: "..", wantErr: true},
+		{name: "trailing parent", key: "1/" + pid + "/..", wantErr: true},
+		{name: "dot segment", key: "1/./" + hash, wantErr: true},
+		{name: "absolute path", key: "/etc/passwd", wantErr: true},
+		{name: "absolute canonical", key: "/1/" + pid + "/" + hash, wantErr: true},
+		{name: "backslash separator", key: "1\\" + pid + "\\" + hash, wantErr: true},
+		{name: "backslash traversal", key: "1/..\\..\\" + hash, wantErr: true},
+		{name: "windows drive", key: "C:\\Windows\\" + hash, wantErr: true},
+		{name: "nul byte", key: "1/" + pid + "/" + hash + "\x00.csv", wantErr: true},
+		{name: "double slash", key: "1//" + hash, wantErr: true},
+		{name: "trailing slash", key: "1/" + pid + "/", wantErr: true},
+		{name: "leading zero chain with traversal", key: "01/../" + hash, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateKey(tt.key)
+			if tt.wantErr && err == nil {
+				t.Fatalf("validateKey(%q) = nil, want error", tt.key)
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("validateKey(%q) error: %v", tt.key, err)
+			}
+		})
+	}
+}
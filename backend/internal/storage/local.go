@@ -0,0 +1,231 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStore 本地磁盘存储，对象key直接映射为根目录下的相对路径
+type LocalStore struct {
+	root string
+	// realRoot 解析符号链接后的根目录，用于检查路径没有经符号链接逃逸出根目录
+	realRoot string
+}
+
+// NewLocalStore 创建本地磁盘存储，根目录不存在时自动创建
+func NewLocalStore(root string) (*LocalStore, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("invalid storage root %s: %w", root, err)
+	}
+	if err := os.MkdirAll(absRoot, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create storage root: %w", err)
+	}
+	realRoot, err := filepath.EvalSymlinks(absRoot)
+	if err != nil {
+		return nil, fmt.Errorf("invalid storage root %s: %w", root, err)
+	}
+	return &LocalStore{root: absRoot, realRoot: realRoot}, nil
+}
+
+// Root 返回存储根目录
+func (s *LocalStore) Root() string {
+	return s.root
+}
+
+// path 将对象key转换为磁盘路径，所有读写都经过这里检查路径位于根目录内
+func (s *LocalStore) path(key string) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	p := filepath.Join(s.root, filepath.FromSlash(key))
+	if err := s.contain(p); err != nil {
+		return "", fmt.Errorf("invalid blob key %q: %w", key, err)
+	}
+	return p, nil
+}
+
+// contain 检查磁盘路径位于根目录内，并且路径上已存在的部分经符号链接解析后仍在根目录内
+func (s *LocalStore) contain(p string) error {
+	if !within(s.root, p) {
+		return errors.New("path escapes storage root")
+	}
+	// 从目标路径向上找到第一个已存在的路径，解析其中的符号链接
+	for dir := p; ; dir = filepath.Dir(dir) {
+		real, err := filepath.EvalSymlinks(dir)
+		if err == nil {
+			if !within(s.realRoot, real) {
+				return errors.New("path escapes storage root through a symlink")
+			}
+			return nil
+		}
+		if !os.IsNotExist(err) {
+			return err
+		}
+		if dir == s.root {
+			return nil
+		}
+	}
+}
+
+// within 判断路径p是否为root或其下的路径（两者均为绝对路径）
+func within(root, p string) bool {
+	rel, err := filepath.Rel(root, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// Put 先写入同目录下的临时文件，再重命名为目标文件，避免读到写了一半的文件
+func (s *LocalStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	dst, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, r)
+	if err == nil {
+		err = tmp.Chmod(0644)
+	}
+	if err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return fmt.Errorf("failed to store %s: %w", key, err)
+	}
+	return nil
+}
+
+// Import 将本地文件移动到key对应的位置：同一文件系统内直接重命名（原子操作），否则复制后删除源文件
+func (s *LocalStore) Import(ctx context.Context, key, src string) error {
+	dst, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	// 跨文件系统时无法重命名，退化为复制（Put内部仍通过临时文件和重命名保证原子性）
+	f, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", src, err)
+	}
+	defer f.Close()
+	if err := s.Put(ctx, key, f, -1, ""); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+// Get 打开对象对应的文件
+func (s *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, *BlobInfo, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Open(p)
+	if os.IsNotExist(err) {
+		return nil, nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	if fi.IsDir() {
+		f.Close()
+		return nil, nil, ErrNotFound
+	}
+	return f, s.info(key, fi), nil
+}
+
+// Stat 获取对象对应文件的元数据
+func (s *LocalStore) Stat(ctx context.Context, key string) (*BlobInfo, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := os.Stat(p)
+	if os.IsNotExist(err) || (err == nil && fi.IsDir()) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.info(key, fi), nil
+}
+
+// Delete 删除对象对应的文件
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete %s: %w", key, err)
+	}
+	return nil
+}
+
+// List 遍历根目录，返回key以prefix开头的所有文件（跳过写入中的临时文件）
+func (s *LocalStore) List(ctx context.Context, prefix string) ([]BlobInfo, error) {
+	var blobs []BlobInfo
+	err := filepath.Walk(s.root, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if fi.IsDir() || strings.HasPrefix(fi.Name(), ".upload-") {
+			return nil
+		}
+		relPath, err := filepath.Rel(s.root, p)
+		if err != nil {
+			return err
+		}
+		if key := filepath.ToSlash(relPath); strings.HasPrefix(key, prefix) {
+			blobs = append(blobs, *s.info(key, fi))
+		}
+		return nil
+	})
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to list %s: %w", s.root, err)
+	}
+	return blobs, nil
+}
+
+func (s *LocalStore) info(key string, fi os.FileInfo) *BlobInfo {
+	return &BlobInfo{
+		Key:         key,
+		Size:        fi.Size(),
+		ContentType: mime.TypeByExtension(path.Ext(key)),
+		ModTime:     fi.ModTime(),
+	}
+}
@@ -0,0 +1,218 @@
+package storage
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWithin(t *testing.T) {
+	root := filepath.FromSlash("/srv/uploads")
+
+	tests := []struct {
+		name string
+		p    string
+		want bool
+	}{
+		{name: "root itself", p: root, want: true},
+		{name: "child", p: filepath.Join(root, "1", "0xab", "f"), want: true},
+		{name: "dot-dot prefixed name", p: filepath.Join(root, "..data"), want: true},
+		{name: "parent", p: filepath.Dir(root), want: false},
+		{name: "sibling with common prefix", p: root + "-other", want: false},
+		{name: "escape through parent", p: filepath.Join(root, "..", "etc", "passwd"), want: false},
+		{name: "unrelated", p: filepath.FromSlash("/etc/passwd"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := within(root, tt.p); got != tt.want {
+				t.Fatalf("within(%q, %q) = %v, want %v", root, tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalStorePathRejectsMaliciousKeys(t *testing.T) {
+	s, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{
+		"../outside",
+		"1/../../outside",
+		"/etc/passwd",
+		"1\\..\\..\\outside",
+		"1/0xab/file\x00",
+		"",
+	} {
+		if p, err := s.path(key); err == nil {
+			t.Errorf("path(%q) = %q, want error", key, p)
+		}
+	}
+}
+
+func TestLocalStoreContainSymlinks(t *testing.T) {
+	base := t.TempDir()
+	outside := filepath.Join(base, "outside")
+	if err := os.MkdirAll(outside, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(outside, "secret"), []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewLocalStore(filepath.Join(base, "root"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := s.Root()
+	if err := os.MkdirAll(filepath.Join(root, "1", "inside"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	symlink := func(target, name string) {
+		t.Helper()
+		if err := os.Symlink(target, filepath.Join(root, name)); err != nil {
+			t.Skipf("symlinks are not supported: %v", err)
+		}
+	}
+	// 指向根目录外的目录和文件，以及根目录内的目录
+	symlink(outside, "escape")
+	symlink(filepath.Join(outside, "secret"), "1/secret")
+	symlink(filepath.Join(root, "1", "inside"), "alias")
+
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{name: "regular new file", key: "1/0xab/new"},
+		{name: "inside existing dir", key: "1/inside/new"},
+		{name: "symlink inside root", key: "alias/new"},
+		{name: "directory symlink escape", key: "escape/secret", wantErr: true},
+		{name: "new file under escaping symlink", key: "escape/sub/new", wantErr: true},
+		{name: "file symlink escape", key: "1/secret", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.path(tt.key)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("path(%q) error: %v", tt.key, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), "symlink") {
+				t.Fatalf("path(%q) error = %v, want symlink escape", tt.key, err)
+			}
+			if _, _, err := s.Get(t.Context(), tt.key); err == nil {
+				t.Fatalf("Get(%q) succeeded through a symlink escape", tt.key)
+			}
+		})
+	}
+
+	if err := s.Put(t.Context(), "escape/planted", strings.NewReader("x"), 1, ""); err == nil {
+		t.Error("Put through a symlink escape succeeded")
+	}
+	if _, err := os.Stat(filepath.Join(outside, "planted")); !os.IsNotExist(err) {
+		t.Errorf("file written outside the storage root: %v", err)
+	}
+}
+
+func TestLocalStoreRoundTrip(t *testing.T) {
+	s, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := t.Context()
+
+	hash := strings.Repeat("ab", 32)
+	objects := map[string]string{
+		"1/0x" + strings.Repeat("01", 32) + "/" + hash: "a,b\n1,2\n",
+		"1/legacy-project/" + hash + ".csv":            "legacy",
+		"2/0x" + strings.Repeat("02", 32) + "/" + hash: "chain 2",
+	}
+	for key, content := range objects {
+		if err := s.Put(ctx, key, strings.NewReader(content), int64(len(content)), "text/csv"); err != nil {
+			t.Fatalf("Put(%q): %v", key, err)
+		}
+	}
+
+	// Import移动暂存的文件，源文件不再存在
+	staged, err := StageFile(t.TempDir(), strings.NewReader("imported"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	importKey := "1/0x" + strings.Repeat("03", 32) + "/" + staged.Hash
+	if err := s.Import(ctx, importKey, staged.Path); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if _, err := os.Stat(staged.Path); !os.IsNotExist(err) {
+		t.Errorf("staged file still exists after Import: %v", err)
+	}
+	objects[importKey] = "imported"
+
+	for key, content := range objects {
+		r, info, err := s.Get(ctx, key)
+		if err != nil {
+			t.Fatalf("Get(%q): %v", key, err)
+		}
+		data, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != content || info.Key != key || info.Size != int64(len(content)) {
+			t.Fatalf("Get(%q) = %q %+v, want %q", key, data, info, content)
+		}
+		if info, err := s.Stat(ctx, key); err != nil || info.Size != int64(len(content)) {
+			t.Fatalf("Stat(%q) = %+v, %v", key, info, err)
+		}
+	}
+
+	// 写入中的临时文件和目录不出现在列表中
+	if err := os.WriteFile(filepath.Join(s.Root(), "1", ".upload-123"), []byte("partial"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	blobs, err := s.List(ctx, "1/")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	var listed []string
+	for _, b := range blobs {
+		listed = append(listed, b.Key)
+	}
+	var want []string
+	for key := range objects {
+		if strings.HasPrefix(key, "1/") {
+			want = append(want, key)
+		}
+	}
+	slices.Sort(listed)
+	slices.Sort(want)
+	if !slices.Equal(listed, want) {
+		t.Fatalf("List(1/) = %v, want %v", listed, want)
+	}
+
+	// 目录不是对象
+	if _, err := s.Stat(ctx, "1"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Stat of a directory error = %v, want ErrNotFound", err)
+	}
+	for key := range objects {
+		if err := s.Delete(ctx, key); err != nil {
+			t.Fatalf("Delete(%q): %v", key, err)
+		}
+		if _, _, err := s.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("Get(%q) after delete error = %v, want ErrNotFound", key, err)
+		}
+		if _, err := s.Stat(ctx, key); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("Stat(%q) after delete error = %v, want ErrNotFound", key, err)
+		}
+		if err := s.Delete(ctx, key); err != nil {
+			t.Fatalf("Delete(%q) of missing object: %v", key, err)
+		}
+	}
+}
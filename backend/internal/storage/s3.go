@@ -0,0 +1,362 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"oracle-backend/internal/config"
+)
+
+const (
+	// s3UnsignedPayload 请求体不参与签名，用于流式上传
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	// s3EmptyPayloadHash 空请求体的SHA-256
+	s3EmptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	s3TimeFormat       = "20060102T150405Z"
+	s3DateFormat       = "20060102"
+	s3Algorithm        = "AWS4-HMAC-SHA256"
+	s3RequestTimeout   = 5 * time.Minute
+)
+
+// S3Store S3兼容的对象存储（AWS S3、MinIO等），使用SigV4签名直接调用REST API
+type S3Store struct {
+	cfg      config.S3Config
+	endpoint *url.URL
+	client   *http.Client
+}
+
+// NewS3Store 创建S3兼容的对象存储
+func NewS3Store(cfg config.S3Config) (*S3Store, error) {
+	endpoint, err := url.Parse(strings.TrimRight(cfg.Endpoint, "/"))
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint: %q", cfg.Endpoint)
+	}
+	cfg.Prefix = strings.Trim(cfg.Prefix, "/")
+	if cfg.Prefix != "" {
+		cfg.Prefix += "/"
+	}
+	return &S3Store{
+		cfg:      cfg,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: s3RequestTimeout},
+	}, nil
+}
+
+// Put 上传对象，size未知时先读入内存以确定Content-Length
+func (s *S3Store) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	if size < 0 {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", key, err)
+		}
+		r, size = bytes.NewReader(data), int64(len(data))
+	}
+
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	resp, err := s.do(ctx, http.MethodPut, s.objectPath(key), nil, header, r, size, s3UnsignedPayload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3Error(resp, "put", key)
+	}
+	return nil
+}
+
+// Get 下载对象
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, *BlobInfo, error) {
+	if err := validateKey(key); err != nil {
+		return nil, nil, err
+	}
+	resp, err := s.do(ctx, http.MethodGet, s.objectPath(key), nil, nil, nil, 0, s3EmptyPayloadHash)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, nil, s3Error(resp, "get", key)
+	}
+	return resp.Body, objectInfo(key, resp), nil
+}
+
+// Stat 获取对象的元数据（HEAD请求）
+func (s *S3Store) Stat(ctx context.Context, key string) (*BlobInfo, error) {
+	if err := validateKey(key); err != nil {
+		return nil, err
+	}
+	resp, err := s.do(ctx, http.MethodHead, s.objectPath(key), nil, nil, nil, 0, s3EmptyPayloadHash)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, s3Error(resp, "stat", key)
+	}
+	return objectInfo(key, resp), nil
+}
+
+// Delete 删除对象
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+	resp, err := s.do(ctx, http.MethodDelete, s.objectPath(key), nil, nil, nil, 0, s3EmptyPayloadHash)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return s3Error(resp, "delete", key)
+	}
+}
+
+// s3ListResult ListObjectsV2的响应
+type s3ListResult struct {
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	Contents              []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+}
+
+// List 使用ListObjectsV2分页列出指定前缀下的所有对象
+func (s *S3Store) List(ctx context.Context, prefix string) ([]BlobInfo, error) {
+	var blobs []BlobInfo
+	token := ""
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", s.cfg.Prefix+prefix)
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := s.do(ctx, http.MethodGet, s.bucketPath(), query, nil, nil, 0, s3EmptyPayloadHash)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			err := s3Error(resp, "list", prefix)
+			resp.Body.Close()
+			return nil, err
+		}
+		var result s3ListResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode s3 list response: %w", err)
+		}
+
+		for _, obj := range result.Contents {
+			blobs = append(blobs, BlobInfo{
+				Key:     strings.TrimPrefix(obj.Key, s.cfg.Prefix),
+				Size:    obj.Size,
+				ModTime: obj.LastModified,
+			})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return blobs, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// bucketPath 返回bucket的URI路径（已编码）
+func (s *S3Store) bucketPath() string {
+	basePath := strings.TrimRight(s.endpoint.EscapedPath(), "/")
+	if s.cfg.PathStyle {
+		return basePath + "/" + s3URIEncode(s.cfg.Bucket, false)
+	}
+	return basePath + "/"
+}
+
+// objectPath 返回对象的URI路径（已编码）
+func (s *S3Store) objectPath(key string) string {
+	return strings.TrimRight(s.bucketPath(), "/") + "/" + s3URIEncode(s.cfg.Prefix+key, true)
+}
+
+// host 返回请求的Host，虚拟主机风格时在endpoint前加上bucket名
+func (s *S3Store) host() string {
+	if s.cfg.PathStyle {
+		return s.endpoint.Host
+	}
+	return s.cfg.Bucket + "." + s.endpoint.Host
+}
+
+// do 构造、签名并发送请求
+func (s *S3Store) do(ctx context.Context, method, escapedPath string, query url.Values, header http.Header,
+	body io.Reader, size int64, payloadHash string) (*http.Response, error) {
+	rawQuery := s3CanonicalQuery(query)
+	target := s.endpoint.Scheme + "://" + s.host() + escapedPath
+	if rawQuery != "" {
+		target += "?" + rawQuery
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build s3 request: %w", err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	signS3Request(req, escapedPath, rawQuery, payloadHash, s.cfg.Region, s.cfg.AccessKey, s.cfg.SecretKey, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("s3 %s %s failed: %w", method, escapedPath, err)
+	}
+	return resp, nil
+}
+
+// signS3Request 按AWS Signature Version 4为请求添加Authorization头
+// 参与签名的头为host以及签名时req.Header中已有的所有头
+func signS3Request(req *http.Request, escapedPath, rawQuery, payloadHash, region, accessKey, secretKey string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format(s3TimeFormat)
+	date := now.Format(s3DateFormat)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		escapedPath,
+		rawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{s3Algorithm, amzDate, scope, hex.EncodeToString(requestHash[:])}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3URIEncode 按SigV4规则编码：仅保留A-Z、a-z、0-9和-._~，keepSlash为true时保留/
+func s3URIEncode(s string, keepSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '.', c == '_', c == '~', c == '/' && keepSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// s3CanonicalQuery 按key排序并编码查询参数
+func s3CanonicalQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, s3URIEncode(k, false)+"="+s3URIEncode(v, false))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// objectInfo 从响应头中读取对象的元数据
+func objectInfo(key string, resp *http.Response) *BlobInfo {
+	info := &BlobInfo{
+		Key:         key,
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+	if size, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64); err == nil {
+		info.Size = size
+	}
+	if t, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.ModTime = t
+	}
+	return info
+}
+
+// s3Error 将非成功的响应转换为错误，尽量带上S3返回的错误码和信息
+func s3Error(resp *http.Response, op, key string) error {
+	var e struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if xml.Unmarshal(body, &e) == nil && e.Code != "" {
+		return fmt.Errorf("s3 %s %s: %s (%s: %s)", op, key, resp.Status, e.Code, e.Message)
+	}
+	return fmt.Errorf("s3 %s %s: %s", op, key, resp.Status)
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+)
+
+// StagedFile 已写入临时文件、尚未提交到存储后端的上传文件
+type StagedFile struct {
+	// Path 临时文件路径
+	Path string
+	// Hash 文件内容的SHA-256（小写十六进制，无0x前缀）
+	Hash string
+	Size int64
+}
+
+// StageFile 将r流式写入dir下的临时文件，同时计算SHA-256，避免将整个文件读入内存
+// 失败时删除已写入的部分内容
+func StageFile(dir string, r io.Reader) (*StagedFile, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, "stage-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging file: %w", err)
+	}
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), r)
+	if err == nil {
+		// CreateTemp创建的文件仅所有者可读写，提交后应与普通上传文件权限一致
+		err = tmp.Chmod(0644)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return nil, fmt.Errorf("failed to stage file: %w", err)
+	}
+
+	return &StagedFile{
+		Path: tmp.Name(),
+		Hash: hex.EncodeToString(hash.Sum(nil)),
+		Size: size,
+	}, nil
+}
+
+// Remove 删除临时文件，文件已提交（被移走）时不做任何事
+func (f *StagedFile) Remove() {
+	if f != nil {
+		os.Remove(f.Path)
+	}
+}
+
+// fileImporter 可以直接接收本地文件的存储后端（如本地磁盘通过重命名移动文件）
+type fileImporter interface {
+	Import(ctx context.Context, key, path string) error
+}
+
+// Commit 将临时文件提交到存储后端的key下
+// 本地磁盘存储直接重命名临时文件，其他后端读取临时文件上传；成功后临时文件不再可用
+func Commit(ctx context.Context, bs BlobStore, key string, f *StagedFile, contentType string) error {
+	if importer, ok := bs.(fileImporter); ok {
+		return importer.Import(ctx, key, f.Path)
+	}
+
+	src, err := os.Open(f.Path)
+	if err != nil {
+		return fmt.Errorf("failed to open staged file: %w", err)
+	}
+	defer src.Close()
+	if err := bs.Put(ctx, key, src, f.Size, contentType); err != nil {
+		return err
+	}
+	f.Remove()
+	return nil
+}
@@ -0,0 +1,43 @@
+package store
+
+import (
+	"oracle-backend/internal/models"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// bucketProjectAccess 项目的文件下载权限，key: <chainId>/<pid>
+var bucketProjectAccess = []byte("project_access")
+
+// ProjectAccessStore 项目下载权限存储接口
+type ProjectAccessStore interface {
+	// ProjectAccess 获取项目的下载权限，未设置时返回nil
+	ProjectAccess(chainID uint64, pid string) (*models.ProjectAccess, error)
+	// SaveProjectAccess 保存项目的下载权限
+	SaveProjectAccess(a models.ProjectAccess) error
+}
+
+// Store 实现ProjectAccessStore
+var _ ProjectAccessStore = (*Store)(nil)
+
+// ProjectAccess 获取项目的下载权限，未设置时返回nil
+func (s *Store) ProjectAccess(chainID uint64, pid string) (*models.ProjectAccess, error) {
+	var a models.ProjectAccess
+	var found bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		found, err = getJSON(tx.Bucket(bucketProjectAccess), projectKey(chainID, pid), &a)
+		return err
+	})
+	if err != nil || !found {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// SaveProjectAccess 保存项目的下载权限
+func (s *Store) SaveProjectAccess(a models.ProjectAccess) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return putJSON(tx.Bucket(bucketProjectAccess), projectKey(a.ChainID, a.Pid), a)
+	})
+}
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"oracle-backend/internal/models"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// bucketAPIKeys API密钥，key: <密钥ID>
+var bucketAPIKeys = []byte("api_keys")
+
+// ErrAPIKeyNotFound API密钥不存在或已过期
+var ErrAPIKeyNotFound = errors.New("api key not found or expired")
+
+// APIKeyStore API密钥存储接口
+type APIKeyStore interface {
+	// SaveAPIKey 保存API密钥
+	SaveAPIKey(k models.APIKey) error
+	// APIKey 获取未过期的API密钥（包括已吊销的），不存在或已过期时返回ErrAPIKeyNotFound
+	APIKey(id string, now time.Time) (*models.APIKey, error)
+	// APIKeysByAddress 获取地址创建的所有未过期API密钥
+	APIKeysByAddress(address string, now time.Time) ([]models.APIKey, error)
+	// RevokeAPIKey 吊销API密钥，已吊销的密钥保持原吊销时间
+	RevokeAPIKey(id string, now time.Time) (*models.APIKey, error)
+}
+
+// Store 实现APIKeyStore
+var _ APIKeyStore = (*Store)(nil)
+
+// SaveAPIKey 保存API密钥
+func (s *Store) SaveAPIKey(k models.APIKey) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return putJSON(tx.Bucket(bucketAPIKeys), k.ID, k)
+	})
+}
+
+// APIKey 获取未过期的API密钥（包括已吊销的），不存在或已过期时返回ErrAPIKeyNotFound
+func (s *Store) APIKey(id string, now time.Time) (*models.APIKey, error) {
+	var k models.APIKey
+	var found bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		found, err = getJSON(tx.Bucket(bucketAPIKeys), id, &k)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found || !now.Before(k.ExpiresAt) {
+		return nil, ErrAPIKeyNotFound
+	}
+	return &k, nil
+}
+
+// APIKeysByAddress 获取地址创建的所有未过期API密钥
+func (s *Store) APIKeysByAddress(address string, now time.Time) ([]models.APIKey, error) {
+	keys := []models.APIKey{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return scanJSON(tx.Bucket(bucketAPIKeys), "", func(v []byte) error {
+			var k models.APIKey
+			if err := json.Unmarshal(v, &k); err != nil {
+				return err
+			}
+			if strings.EqualFold(k.Address, address) && now.Before(k.ExpiresAt) {
+				keys = append(keys, k)
+			}
+			return nil
+		})
+	})
+	return keys, err
+}
+
+// RevokeAPIKey 吊销API密钥，已吊销的密钥保持原吊销时间
+func (s *Store) RevokeAPIKey(id string, now time.Time) (*models.APIKey, error) {
+	var k models.APIKey
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketAPIKeys)
+		found, err := getJSON(b, id, &k)
+		if err != nil {
+			return err
+		}
+		if !found || !now.Before(k.ExpiresAt) {
+			return ErrAPIKeyNotFound
+		}
+		if k.RevokedAt != nil {
+			return nil
+		}
+		k.RevokedAt = &now
+		return putJSON(b, id, k)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
@@ -0,0 +1,110 @@
+package store
+
+import (
+	"errors"
+	"time"
+
+	"oracle-backend/internal/models"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// bucketAuthNonces 已签发未使用的登录nonce，key: <nonce>
+	bucketAuthNonces = []byte("auth_nonces")
+	// bucketSessions 登录会话，key: <令牌哈希>
+	bucketSessions = []byte("sessions")
+)
+
+// ErrSessionNotFound 会话不存在或已过期
+var ErrSessionNotFound = errors.New("session not found or expired")
+
+// AuthStore SIWE登录所需的nonce和会话存储接口
+type AuthStore interface {
+	// SaveAuthNonce 保存签发的登录nonce
+	SaveAuthNonce(n models.AuthNonce) error
+	// ConsumeAuthNonce 删除未过期的登录nonce，nonce不存在、已使用或已过期时返回ErrNonceInvalid
+	ConsumeAuthNonce(nonce string, now time.Time) error
+	// SaveSession 保存会话
+	SaveSession(s models.Session) error
+	// Session 获取未过期的会话，不存在或已过期时返回ErrSessionNotFound
+	Session(tokenHash string, now time.Time) (*models.Session, error)
+	// DeleteSession 删除会话
+	DeleteSession(tokenHash string) error
+	// PruneAuthState 删除已过期的登录nonce、会话和API密钥，返回删除的条目数
+	PruneAuthState(now time.Time) (int, error)
+}
+
+// Store 实现AuthStore
+var _ AuthStore = (*Store)(nil)
+
+// SaveAuthNonce 保存签发的登录nonce
+func (s *Store) SaveAuthNonce(n models.AuthNonce) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return putJSON(tx.Bucket(bucketAuthNonces), n.Nonce, n)
+	})
+}
+
+// ConsumeAuthNonce 删除未过期的登录nonce，nonce不存在、已使用或已过期时返回ErrNonceInvalid
+func (s *Store) ConsumeAuthNonce(nonce string, now time.Time) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketAuthNonces)
+		var n models.AuthNonce
+		found, err := getJSON(b, nonce, &n)
+		if err != nil {
+			return err
+		}
+		if !found || !now.Before(n.ExpiresAt) {
+			return ErrNonceInvalid
+		}
+		return b.Delete([]byte(nonce))
+	})
+}
+
+// SaveSession 保存会话
+func (s *Store) SaveSession(sess models.Session) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return putJSON(tx.Bucket(bucketSessions), sess.TokenHash, sess)
+	})
+}
+
+// Session 获取未过期的会话，不存在或已过期时返回ErrSessionNotFound
+func (s *Store) Session(tokenHash string, now time.Time) (*models.Session, error) {
+	var sess models.Session
+	var found bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		found, err = getJSON(tx.Bucket(bucketSessions), tokenHash, &sess)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found || !now.Before(sess.ExpiresAt) {
+		return nil, ErrSessionNotFound
+	}
+	return &sess, nil
+}
+
+// DeleteSession 删除会话
+func (s *Store) DeleteSession(tokenHash string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketSessions).Delete([]byte(tokenHash))
+	})
+}
+
+// PruneAuthState 删除已过期的登录nonce、会话和API密钥，返回删除的条目数
+func (s *Store) PruneAuthState(now time.Time) (int, error) {
+	pruned := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{bucketAuthNonces, bucketSessions, bucketAPIKeys} {
+			n, err := pruneExpired(tx.Bucket(name), now)
+			if err != nil {
+				return err
+			}
+			pruned += n
+		}
+		return nil
+	})
+	return pruned, err
+}
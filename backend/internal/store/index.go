@@ -0,0 +1,181 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"oracle-backend/internal/models"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// bucketCheckpoints 每条链的索引进度，key: <chainId>
+	bucketCheckpoints = []byte("index_checkpoints")
+	// bucketProjects 已索引的项目，key: <chainId>/<pid>
+	bucketProjects = []byte("index_projects")
+	// bucketRecords 已索引的数据记录，key: <chainId>/<pid>/<did>
+	bucketRecords = []byte("index_records")
+)
+
+// IndexReader 读取接口使用的链上事件索引
+type IndexReader interface {
+	// Checkpoint 获取链的索引进度，尚未开始索引时返回nil
+	Checkpoint(chainID uint64) (*models.IndexCheckpoint, error)
+	// IndexedProjects 获取链上已索引的所有项目
+	IndexedProjects(chainID uint64) ([]models.IndexedProject, error)
+	// IndexedRecords 获取项目已索引的所有数据记录（按数据ID排序）
+	IndexedRecords(chainID uint64, pid string) ([]models.IndexedDataRecord, error)
+	// IndexedRecord 获取已索引的单条数据记录，不存在时返回nil
+	IndexedRecord(chainID uint64, pid, did string) (*models.IndexedDataRecord, error)
+}
+
+// Store 实现IndexReader
+var _ IndexReader = (*Store)(nil)
+
+// Checkpoint 获取链的索引进度，尚未开始索引时返回nil
+func (s *Store) Checkpoint(chainID uint64) (*models.IndexCheckpoint, error) {
+	var cp models.IndexCheckpoint
+	var found bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		found, err = getJSON(tx.Bucket(bucketCheckpoints), strconv.FormatUint(chainID, 10), &cp)
+		return err
+	})
+	if err != nil || !found {
+		return nil, err
+	}
+	return &cp, nil
+}
+
+// ApplyIndexBatch 在同一个事务中写入一批索引结果并推进进度
+func (s *Store) ApplyIndexBatch(cp models.IndexCheckpoint, projects []models.IndexedProject, records []models.IndexedDataRecord) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		projectBucket := tx.Bucket(bucketProjects)
+		for _, p := range projects {
+			if err := putJSON(projectBucket, projectKey(p.ChainID, p.Pid), p); err != nil {
+				return err
+			}
+		}
+
+		recordBucket := tx.Bucket(bucketRecords)
+		for _, r := range records {
+			if err := putJSON(recordBucket, recordKey(r.ChainID, r.Pid, r.Did), r); err != nil {
+				return err
+			}
+		}
+
+		return putJSON(tx.Bucket(bucketCheckpoints), strconv.FormatUint(cp.ChainID, 10), cp)
+	})
+}
+
+// RewindIndex 回滚链的索引：删除区块号大于cp.BlockNumber的项目和数据记录，并将进度重置为cp
+// 用于处理超过确认深度的链重组
+func (s *Store) RewindIndex(cp models.IndexCheckpoint) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		prefix := []byte(chainPrefix(cp.ChainID))
+
+		if err := deleteAfter(tx.Bucket(bucketProjects), prefix, cp.BlockNumber); err != nil {
+			return err
+		}
+		if err := deleteAfter(tx.Bucket(bucketRecords), prefix, cp.BlockNumber); err != nil {
+			return err
+		}
+
+		return putJSON(tx.Bucket(bucketCheckpoints), strconv.FormatUint(cp.ChainID, 10), cp)
+	})
+}
+
+// deleteAfter 删除bucket中指定前缀下区块号大于block的条目
+func deleteAfter(b *bolt.Bucket, prefix []byte, block uint64) error {
+	var stale [][]byte
+	c := b.Cursor()
+	for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+		var entry struct {
+			BlockNumber uint64 `json:"blockNumber"`
+		}
+		if err := json.Unmarshal(v, &entry); err != nil {
+			return fmt.Errorf("failed to unmarshal %s: %w", k, err)
+		}
+		if entry.BlockNumber > block {
+			stale = append(stale, append([]byte(nil), k...))
+		}
+	}
+
+	for _, k := range stale {
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IndexedProjects 获取链上已索引的所有项目
+func (s *Store) IndexedProjects(chainID uint64) ([]models.IndexedProject, error) {
+	var projects []models.IndexedProject
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return scanJSON(tx.Bucket(bucketProjects), chainPrefix(chainID), func(v []byte) error {
+			var p models.IndexedProject
+			if err := json.Unmarshal(v, &p); err != nil {
+				return err
+			}
+			projects = append(projects, p)
+			return nil
+		})
+	})
+	return projects, err
+}
+
+// IndexedRecords 获取项目已索引的所有数据记录（按数据ID排序）
+func (s *Store) IndexedRecords(chainID uint64, pid string) ([]models.IndexedDataRecord, error) {
+	var records []models.IndexedDataRecord
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return scanJSON(tx.Bucket(bucketRecords), projectKey(chainID, pid)+"/", func(v []byte) error {
+			var r models.IndexedDataRecord
+			if err := json.Unmarshal(v, &r); err != nil {
+				return err
+			}
+			records = append(records, r)
+			return nil
+		})
+	})
+	return records, err
+}
+
+// IndexedRecord 获取已索引的单条数据记录，不存在时返回nil
+func (s *Store) IndexedRecord(chainID uint64, pid, did string) (*models.IndexedDataRecord, error) {
+	var r models.IndexedDataRecord
+	var found bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		found, err = getJSON(tx.Bucket(bucketRecords), recordKey(chainID, pid, did), &r)
+		return err
+	})
+	if err != nil || !found {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// scanJSON 遍历bucket中指定前缀的所有值
+func scanJSON(b *bolt.Bucket, prefix string, fn func(v []byte) error) error {
+	c := b.Cursor()
+	p := []byte(prefix)
+	for k, v := c.Seek(p); k != nil && bytes.HasPrefix(k, p); k, v = c.Next() {
+		if err := fn(v); err != nil {
+			return fmt.Errorf("failed to read %s: %w", k, err)
+		}
+	}
+	return nil
+}
+
+func projectKey(chainID uint64, pid string) string {
+	return chainPrefix(chainID) + strings.ToLower(pid)
+}
+
+func recordKey(chainID uint64, pid, did string) string {
+	return projectKey(chainID, pid) + "/" + strings.ToLower(did)
+}
@@ -0,0 +1,43 @@
+package store
+
+import (
+	"oracle-backend/internal/models"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// bucketUploadPolicies 项目的上传文件策略，key: <chainId>/<pid>
+var bucketUploadPolicies = []byte("upload_policies")
+
+// UploadPolicyStore 项目上传策略存储接口
+type UploadPolicyStore interface {
+	// UploadPolicy 获取项目的上传策略，未设置时返回nil
+	UploadPolicy(chainID uint64, pid string) (*models.UploadPolicy, error)
+	// SaveUploadPolicy 保存项目的上传策略
+	SaveUploadPolicy(p models.UploadPolicy) error
+}
+
+// Store 实现UploadPolicyStore
+var _ UploadPolicyStore = (*Store)(nil)
+
+// UploadPolicy 获取项目的上传策略，未设置时返回nil
+func (s *Store) UploadPolicy(chainID uint64, pid string) (*models.UploadPolicy, error) {
+	var p models.UploadPolicy
+	var found bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		found, err = getJSON(tx.Bucket(bucketUploadPolicies), projectKey(chainID, pid), &p)
+		return err
+	})
+	if err != nil || !found {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// SaveUploadPolicy 保存项目的上传策略
+func (s *Store) SaveUploadPolicy(p models.UploadPolicy) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return putJSON(tx.Bucket(bucketUploadPolicies), projectKey(p.ChainID, p.Pid), p)
+	})
+}
@@ -0,0 +1,170 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"oracle-backend/internal/models"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// bucketNonces 已签发未使用的nonce，key: <address>/<nonce>
+	bucketNonces = []byte("nonces")
+	// bucketUsedSignatures 已使用的签名摘要，key: <hash>
+	bucketUsedSignatures = []byte("used_signatures")
+)
+
+var (
+	// ErrSignatureUsed 签名已被使用过
+	ErrSignatureUsed = errors.New("signature already used")
+	// ErrNonceInvalid nonce不是签发给该地址的、已被使用或已过期
+	ErrNonceInvalid = errors.New("nonce is invalid, used or expired")
+)
+
+// ReplayStore 防重放所需的nonce和已使用签名的存储接口
+type ReplayStore interface {
+	// SaveNonce 保存签发的nonce，同时删除该地址已过期的nonce；地址未过期的nonce达到limit个时替换最早签发的
+	SaveNonce(n models.IssuedNonce, limit int) error
+	// CheckSignature 检查签名未被使用且nonce（为空时跳过）有效，不做任何修改
+	// 签名在SaveSubmission保存提交时才与元数据在同一事务中被记录
+	CheckSignature(sig models.UsedSignature, nonce string) error
+	// PruneReplayState 删除已过期的nonce和签名记录，返回删除的条目数
+	PruneReplayState(now time.Time) (int, error)
+}
+
+// Store 实现ReplayStore
+var _ ReplayStore = (*Store)(nil)
+
+// SaveNonce 保存签发的nonce，同时删除该地址已过期的nonce；地址未过期的nonce达到limit个时替换最早签发的
+func (s *Store) SaveNonce(n models.IssuedNonce, limit int) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketNonces)
+		prefix := []byte(nonceKey(n.Address, ""))
+
+		var (
+			stale       [][]byte
+			outstanding []models.IssuedNonce
+		)
+		c := b.Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var issued models.IssuedNonce
+			if err := json.Unmarshal(v, &issued); err != nil {
+				return err
+			}
+			if !n.IssuedAt.Before(issued.ExpiresAt) {
+				stale = append(stale, append([]byte(nil), k...))
+				continue
+			}
+			outstanding = append(outstanding, issued)
+		}
+		if limit > 0 && len(outstanding) >= limit {
+			sort.Slice(outstanding, func(i, j int) bool {
+				return outstanding[i].IssuedAt.Before(outstanding[j].IssuedAt)
+			})
+			for _, issued := range outstanding[:len(outstanding)-limit+1] {
+				stale = append(stale, []byte(nonceKey(issued.Address, issued.Nonce)))
+			}
+		}
+		for _, k := range stale {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return putJSON(b, nonceKey(n.Address, n.Nonce), n)
+	})
+}
+
+// CheckSignature 检查签名未被使用且nonce（为空时跳过）有效，不做任何修改
+func (s *Store) CheckSignature(sig models.UsedSignature, nonce string) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		_, err := checkSignature(tx, sig, nonce)
+		return err
+	})
+}
+
+// checkSignature 在事务中检查签名未被使用且nonce有效，返回nonce的key（nonce为空时为空字符串）
+func checkSignature(tx *bolt.Tx, sig models.UsedSignature, nonce string) (string, error) {
+	if tx.Bucket(bucketUsedSignatures).Get([]byte(strings.ToLower(sig.Hash))) != nil {
+		return "", ErrSignatureUsed
+	}
+	if nonce == "" {
+		return "", nil
+	}
+
+	key := nonceKey(sig.Signer, nonce)
+	var n models.IssuedNonce
+	found, err := getJSON(tx.Bucket(bucketNonces), key, &n)
+	if err != nil {
+		return "", err
+	}
+	if !found || !sig.UsedAt.Before(n.ExpiresAt) {
+		return "", ErrNonceInvalid
+	}
+	return key, nil
+}
+
+// consumeSignature 在事务中检查签名未被使用、消费nonce（nonce为空时跳过）并记录签名
+func consumeSignature(tx *bolt.Tx, sig models.UsedSignature, nonce string) error {
+	key, err := checkSignature(tx, sig, nonce)
+	if err != nil {
+		return err
+	}
+	if key != "" {
+		if err := tx.Bucket(bucketNonces).Delete([]byte(key)); err != nil {
+			return err
+		}
+	}
+	return putJSON(tx.Bucket(bucketUsedSignatures), strings.ToLower(sig.Hash), sig)
+}
+
+// PruneReplayState 删除已过期的nonce和签名记录，返回删除的条目数
+func (s *Store) PruneReplayState(now time.Time) (int, error) {
+	pruned := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{bucketNonces, bucketUsedSignatures} {
+			n, err := pruneExpired(tx.Bucket(name), now)
+			if err != nil {
+				return err
+			}
+			pruned += n
+		}
+		return nil
+	})
+	return pruned, err
+}
+
+// pruneExpired 删除bucket中expiresAt不晚于now的条目，返回删除的条目数
+func pruneExpired(b *bolt.Bucket, now time.Time) (int, error) {
+	var expired [][]byte
+	err := b.ForEach(func(k, v []byte) error {
+		var entry struct {
+			ExpiresAt time.Time `json:"expiresAt"`
+		}
+		if err := json.Unmarshal(v, &entry); err != nil {
+			return err
+		}
+		if !now.Before(entry.ExpiresAt) {
+			expired = append(expired, append([]byte(nil), k...))
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, k := range expired {
+		if err := b.Delete(k); err != nil {
+			return 0, err
+		}
+	}
+	return len(expired), nil
+}
+
+func nonceKey(address, nonce string) string {
+	return strings.ToLower(address) + "/" + nonce
+}
@@ -0,0 +1,109 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Store 基于BoltDB的本地持久化存储，保存索引数据等后端状态
+type Store struct {
+	db *bolt.DB
+}
+
+// Open 打开（不存在时创建）数据库文件，并创建所需的bucket
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range allBuckets {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return fmt.Errorf("failed to create bucket %s: %w", name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &Store{db: db}, nil
+}
+
+// Close 关闭数据库
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// allBuckets 数据库中的所有bucket
+var allBuckets = [][]byte{
+	bucketCheckpoints,
+	bucketProjects,
+	bucketRecords,
+	bucketSubmissions,
+	bucketUploads,
+	bucketFileHashes,
+	bucketNonces,
+	bucketUsedSignatures,
+	bucketAuthNonces,
+	bucketSessions,
+	bucketAPIKeys,
+	bucketProjectAccess,
+	bucketUploadPolicies,
+}
+
+// chainPrefix 按链划分的key前缀
+func chainPrefix(chainID uint64) string {
+	return strconv.FormatUint(chainID, 10) + "/"
+}
+
+// putJSON 将值序列化为JSON后写入bucket
+func putJSON(b *bolt.Bucket, key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", key, err)
+	}
+	return b.Put([]byte(key), data)
+}
+
+// getJSON 读取bucket中的JSON值，不存在时返回false
+func getJSON(b *bolt.Bucket, key string, value interface{}) (bool, error) {
+	data := b.Get([]byte(key))
+	if data == nil {
+		return false, nil
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		return false, fmt.Errorf("failed to unmarshal %s: %w", key, err)
+	}
+	return true, nil
+}
+
+// deletePrefix 删除bucket中指定前缀的所有条目
+func deletePrefix(b *bolt.Bucket, prefix string) error {
+	var keys [][]byte
+	c := b.Cursor()
+	p := []byte(prefix)
+	for k, _ := c.Seek(p); k != nil && bytes.HasPrefix(k, p); k, _ = c.Next() {
+		keys = append(keys, append([]byte(nil), k...))
+	}
+	for _, k := range keys {
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
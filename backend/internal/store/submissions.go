@@ -0,0 +1,97 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"oracle-backend/internal/models"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// bucketSubmissions 提交记录与文件的对应关系，key: <chainId>/<pid>/<did>
+var bucketSubmissions = []byte("submissions")
+
+// ErrSubmissionExists 同一项目和数据ID已有提交，且不能被本次提交替换
+var ErrSubmissionExists = errors.New("submission already exists")
+
+// SaveSubmission 原子地保存提交记录及其文件元数据
+// 同一项目和数据ID已有提交时，只有replace为true且提交者相同才替换，否则返回ErrSubmissionExists；
+// sig非空时在同一事务中记录签名已使用并消费nonce，签名已使用或nonce无效时不保存任何数据
+func (s *Store) SaveSubmission(m models.SubmissionManifest, uploads []models.UploadRecord, sig *models.UsedSignature, nonce string, replace bool) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		signer, found, err := submissionSigner(tx, m.ChainID, m.Pid, m.Did)
+		if err != nil {
+			return err
+		}
+		if found && (!replace || !strings.EqualFold(signer, m.Signer)) {
+			return ErrSubmissionExists
+		}
+
+		if sig != nil {
+			if err := consumeSignature(tx, *sig, nonce); err != nil {
+				return err
+			}
+		}
+		// 删除被覆盖的提交遗留的文件元数据
+		if err := deletePrefix(tx.Bucket(bucketUploads), recordKey(m.ChainID, m.Pid, m.Did)+"/"); err != nil {
+			return err
+		}
+		if err := saveUploads(tx, uploads); err != nil {
+			return err
+		}
+		return putJSON(tx.Bucket(bucketSubmissions), recordKey(m.ChainID, m.Pid, m.Did), m)
+	})
+}
+
+// submissionSigner 在事务中获取已有提交的提交者地址，早期的提交记录没有Signer时取自其文件元数据
+func submissionSigner(tx *bolt.Tx, chainID uint64, pid, did string) (string, bool, error) {
+	var m models.SubmissionManifest
+	found, err := getJSON(tx.Bucket(bucketSubmissions), recordKey(chainID, pid, did), &m)
+	if err != nil || !found || m.Signer != "" {
+		return m.Signer, found, err
+	}
+	err = scanJSON(tx.Bucket(bucketUploads), recordKey(chainID, pid, did)+"/", func(v []byte) error {
+		var u models.UploadRecord
+		if err := json.Unmarshal(v, &u); err != nil {
+			return err
+		}
+		if m.Signer == "" {
+			m.Signer = u.Signer
+		}
+		return nil
+	})
+	return m.Signer, true, err
+}
+
+// Submission 获取提交记录，不存在时返回nil
+func (s *Store) Submission(chainID uint64, pid, did string) (*models.SubmissionManifest, error) {
+	var m models.SubmissionManifest
+	var found bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		found, err = getJSON(tx.Bucket(bucketSubmissions), recordKey(chainID, pid, did), &m)
+		return err
+	})
+	if err != nil || !found {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// Submissions 获取所有提交记录
+func (s *Store) Submissions() ([]models.SubmissionManifest, error) {
+	var manifests []models.SubmissionManifest
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return scanJSON(tx.Bucket(bucketSubmissions), "", func(v []byte) error {
+			var m models.SubmissionManifest
+			if err := json.Unmarshal(v, &m); err != nil {
+				return err
+			}
+			manifests = append(manifests, m)
+			return nil
+		})
+	})
+	return manifests, err
+}
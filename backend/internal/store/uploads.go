@@ -0,0 +1,167 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"oracle-backend/internal/models"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// bucketUploads 上传文件元数据，key: <chainId>/<pid>/<did>/<fileHash>
+	bucketUploads = []byte("uploads")
+	// bucketFileHashes 文件哈希索引，key: <fileHash>/<chainId>/<pid>
+	bucketFileHashes = []byte("file_hashes")
+)
+
+// UploadRepository 上传元数据的存储接口
+// 一次提交由SubmissionManifest（提交级别）和若干UploadRecord（文件级别）组成
+type UploadRepository interface {
+	// SaveSubmission 原子地保存一次提交及其所有文件的元数据
+	// 同一项目和数据ID已有提交时，只有replace为true且提交者相同才替换，否则返回ErrSubmissionExists；
+	// sig非空时在同一事务中记录签名已使用并消费nonce，签名已使用或nonce无效时不保存任何数据
+	SaveSubmission(manifest models.SubmissionManifest, uploads []models.UploadRecord, sig *models.UsedSignature, nonce string, replace bool) error
+	// Submission 获取提交记录，不存在时返回nil
+	Submission(chainID uint64, pid, did string) (*models.SubmissionManifest, error)
+	// Submissions 获取所有提交记录
+	Submissions() ([]models.SubmissionManifest, error)
+	// Upload 获取单个文件的元数据，不存在时返回nil
+	Upload(chainID uint64, pid, did, fileHash string) (*models.UploadRecord, error)
+	// UploadsBySubmission 获取一次提交的所有文件元数据
+	UploadsBySubmission(chainID uint64, pid, did string) ([]models.UploadRecord, error)
+	// UploadsByProject 获取项目的所有文件元数据
+	UploadsByProject(chainID uint64, pid string) ([]models.UploadRecord, error)
+	// FilesByHash 按完整的文件哈希查找该文件在所有链和项目中的位置，按链和项目排序
+	FilesByHash(fileHash string) ([]models.FileLocation, error)
+	// ProjectFileByHash 在指定链和项目内按完整的文件哈希查找文件位置，不存在时返回nil
+	ProjectFileByHash(chainID uint64, pid, fileHash string) (*models.FileLocation, error)
+	// IndexFile 将文件加入哈希索引，已存在的条目不会被覆盖
+	IndexFile(loc models.FileLocation) error
+}
+
+// Store 实现UploadRepository
+var _ UploadRepository = (*Store)(nil)
+
+// saveUploads 在事务中保存多个文件的元数据，并更新文件哈希索引
+func saveUploads(tx *bolt.Tx, uploads []models.UploadRecord) error {
+	b := tx.Bucket(bucketUploads)
+	hashes := tx.Bucket(bucketFileHashes)
+	for _, u := range uploads {
+		if err := putJSON(b, uploadKey(u.ChainID, u.Pid, u.Did, u.FileHash), u); err != nil {
+			return err
+		}
+		loc := models.FileLocation{
+			ChainID:     u.ChainID,
+			Pid:         u.Pid,
+			FileHash:    u.FileHash,
+			FileName:    u.FileName,
+			ContentType: u.ContentType,
+			StoragePath: u.StoragePath,
+		}
+		if err := putJSON(hashes, fileHashKey(u.ChainID, u.Pid, u.FileHash), loc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Upload 获取单个文件的元数据，不存在时返回nil
+func (s *Store) Upload(chainID uint64, pid, did, fileHash string) (*models.UploadRecord, error) {
+	var u models.UploadRecord
+	var found bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		found, err = getJSON(tx.Bucket(bucketUploads), uploadKey(chainID, pid, did, fileHash), &u)
+		return err
+	})
+	if err != nil || !found {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// UploadsBySubmission 获取一次提交的所有文件元数据
+func (s *Store) UploadsBySubmission(chainID uint64, pid, did string) ([]models.UploadRecord, error) {
+	return s.scanUploads(recordKey(chainID, pid, did) + "/")
+}
+
+// UploadsByProject 获取项目的所有文件元数据
+func (s *Store) UploadsByProject(chainID uint64, pid string) ([]models.UploadRecord, error) {
+	return s.scanUploads(projectKey(chainID, pid) + "/")
+}
+
+func (s *Store) scanUploads(prefix string) ([]models.UploadRecord, error) {
+	var uploads []models.UploadRecord
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return scanJSON(tx.Bucket(bucketUploads), prefix, func(v []byte) error {
+			var u models.UploadRecord
+			if err := json.Unmarshal(v, &u); err != nil {
+				return err
+			}
+			uploads = append(uploads, u)
+			return nil
+		})
+	})
+	return uploads, err
+}
+
+func uploadKey(chainID uint64, pid, did, fileHash string) string {
+	return recordKey(chainID, pid, did) + "/" + normalizeFileHash(fileHash)
+}
+
+// FilesByHash 按完整的文件哈希查找该文件在所有链和项目中的位置，按链和项目排序
+func (s *Store) FilesByHash(fileHash string) ([]models.FileLocation, error) {
+	var locs []models.FileLocation
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return scanJSON(tx.Bucket(bucketFileHashes), normalizeFileHash(fileHash)+"/", func(v []byte) error {
+			var loc models.FileLocation
+			if err := json.Unmarshal(v, &loc); err != nil {
+				return err
+			}
+			locs = append(locs, loc)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file hash index: %w", err)
+	}
+	return locs, nil
+}
+
+// ProjectFileByHash 在指定链和项目内按完整的文件哈希查找文件位置，不存在时返回nil
+func (s *Store) ProjectFileByHash(chainID uint64, pid, fileHash string) (*models.FileLocation, error) {
+	var loc models.FileLocation
+	var found bool
+	err := s.db.View(func(tx *bolt.Tx) error {
+		var err error
+		found, err = getJSON(tx.Bucket(bucketFileHashes), fileHashKey(chainID, pid, fileHash), &loc)
+		return err
+	})
+	if err != nil || !found {
+		return nil, err
+	}
+	return &loc, nil
+}
+
+// IndexFile 将文件加入哈希索引，已存在的条目不会被覆盖
+func (s *Store) IndexFile(loc models.FileLocation) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketFileHashes)
+		key := fileHashKey(loc.ChainID, loc.Pid, loc.FileHash)
+		if b.Get([]byte(key)) != nil {
+			return nil
+		}
+		return putJSON(b, key, loc)
+	})
+}
+
+func fileHashKey(chainID uint64, pid, fileHash string) string {
+	return normalizeFileHash(fileHash) + "/" + projectKey(chainID, pid)
+}
+
+func normalizeFileHash(fileHash string) string {
+	return strings.ToLower(strings.TrimPrefix(fileHash, "0x"))
+}
@@ -1,15 +1,145 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 
 	"oracle-backend/internal/api"
+	"oracle-backend/internal/config"
+	"oracle-backend/internal/indexer"
+	"oracle-backend/internal/service"
+	"oracle-backend/internal/storage"
+	"oracle-backend/internal/store"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	// 加载链配置注册表
+	registry, err := config.LoadChainRegistry("")
+	if err != nil {
+		log.Fatalf("Failed to load chain registry: %v", err)
+	}
+	if len(registry.ChainIDs()) == 0 {
+		log.Printf("Warning: no chains configured, all uploads will be rejected")
+	}
+	service.SetChainRegistry(registry)
+
+	// 创建按链复用的合约客户端连接池
+	pool := service.NewClientPool(registry)
+	defer pool.Close()
+	service.SetClientPool(pool)
+
+	// 打开本地数据库
+	st, err := store.Open(config.DatabasePath())
+	if err != nil {
+		log.Fatalf("Failed to open database: %v", err)
+	}
+	defer st.Close()
+	service.SetUploadRepository(st)
+
+	// 创建上传文件的存储后端
+	blobConfig, err := config.LoadBlobStoreConfig()
+	if err != nil {
+		log.Fatalf("Failed to load storage config: %v", err)
+	}
+	blobStore, err := storage.New(blobConfig)
+	if err != nil {
+		log.Fatalf("Failed to create storage backend: %v", err)
+	}
+	service.SetBlobStore(blobStore)
+	log.Printf("Storage backend: %s", blobConfig.Backend)
+
+	// 上传文件限制：服务端的默认限制和上限，项目的上传策略保存在本地数据库中
+	uploadLimits, err := config.LoadUploadLimits()
+	if err != nil {
+		log.Fatalf("Failed to load upload limits: %v", err)
+	}
+	service.SetUploadLimits(uploadLimits)
+	service.SetUploadPolicyStore(st)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// 在后台逐链核对离线did实现，核对通过前编码和解码did仍调用合约
+	go service.VerifyDidEncodings(ctx)
+
+	// 为已有的上传文件补建哈希索引
+	if n, err := service.BackfillHashIndex(ctx); err != nil {
+		log.Printf("Warning: failed to backfill file hash index: %v", err)
+	} else {
+		log.Printf("File hash index ready, %d files scanned", n)
+	}
+
+	// 启动链上事件索引
+	if config.IndexerEnabled() {
+		if err := indexer.Start(ctx, registry, pool, st); err != nil {
+			log.Fatalf("Failed to start indexer: %v", err)
+		}
+		// 索引追上已确认区块后，项目列表和数据记录的读取接口改为读取索引
+		service.SetIndexReader(st)
+	}
+
+	// 启动本地文件与链上dataHash的对账任务
+	reconcileInterval, err := config.ReconcileInterval()
+	if err != nil {
+		log.Fatalf("Failed to load reconcile config: %v", err)
+	}
+	if reconcileInterval > 0 {
+		service.StartReconciler(ctx, reconcileInterval)
+	}
+
+	// 中继模式：配置了提交者keystore时由后端代为发送交易
+	relayerConfig, err := config.LoadRelayerConfig()
+	if err != nil {
+		log.Fatalf("Failed to load relayer config: %v", err)
+	}
+	if relayerConfig.Enabled() {
+		relayer, err := service.NewRelayer(relayerConfig, pool)
+		if err != nil {
+			log.Fatalf("Failed to create relayer: %v", err)
+		}
+		service.SetRelayer(relayer)
+		log.Printf("Relayer enabled, submitter address: %s", relayer.Address().Hex())
+	}
+
+	// 上传签名验证配置：默认只接受EIP-712签名
+	signatureConfig, err := config.LoadSignatureConfig()
+	if err != nil {
+		log.Fatalf("Failed to load signature config: %v", err)
+	}
+	service.SetSignatureConfig(signatureConfig)
+	if signatureConfig.AllowPersonalSign {
+		log.Printf("Legacy personal_sign upload signatures are enabled")
+	}
+
+	// 防重放：nonce和已使用签名保存在本地数据库中
+	service.SetReplayStore(st)
+	service.StartReplayPruner(ctx)
+
+	// SIWE登录：登录nonce、会话和API密钥保存在本地数据库中
+	authConfig, err := config.LoadAuthConfig()
+	if err != nil {
+		log.Fatalf("Failed to load auth config: %v", err)
+	}
+	service.SetAuthConfig(authConfig)
+	service.SetAuthStore(st)
+	service.SetAPIKeyStore(st)
+	service.StartAuthPruner(ctx)
+
+	// 文件下载访问控制：项目可见性保存在本地数据库中，签名下载链接使用HMAC密钥
+	accessConfig, err := config.LoadAccessConfig()
+	if err != nil {
+		log.Fatalf("Failed to load access config: %v", err)
+	}
+	service.SetAccessConfig(accessConfig)
+	service.SetAccessStore(st)
+	log.Printf("Default project visibility: %s", accessConfig.DefaultVisibility)
+	if accessConfig.EphemeralSecret {
+		log.Printf("Warning: ORACLE_DOWNLOAD_URL_SECRET is not set, signed download URLs will not survive a restart")
+	}
+
 	// 创建Gin引擎
 	router := gin.Default()
 
@@ -17,7 +147,7 @@ func main() {
 	router.Use(func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-API-Key, accept, origin, Cache-Control, X-Requested-With")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
@@ -0,0 +1,198 @@
+// Package coredata 实现Oracle合约coreData字段的二进制格式，与前端dataSerializer.js兼容：
+//
+//	[数量: varint] + ([键长度: varint] + [键: UTF-8字节] + [值: varint])...
+//
+// varint为protobuf风格的无符号变长整数（每字节低7位存数据，最高位表示后面还有字节），值为uint256。
+// 解码时只接受规范编码：varint不能带多余的高位零字节，键不能重复，数据末尾不能有多余字节，
+// 因此同一组键值对（按相同顺序）只有一种编码，coreData的keccak256可以用来比较内容。
+package coredata
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"unicode/utf8"
+)
+
+// maxVarintLen uint256按7位一组编码最多需要的字节数
+const maxVarintLen = 37
+
+// MaxValue 值的上限 2^256-1
+var MaxValue = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+var (
+	// ErrTruncated 数据在varint或键的中间结束，或数量、键长度超出剩余数据
+	ErrTruncated = errors.New("coredata: truncated data")
+	// ErrOverflow 数值超出uint256
+	ErrOverflow = errors.New("coredata: value out of range")
+	// ErrNonCanonical varint带有多余的高位零字节
+	ErrNonCanonical = errors.New("coredata: non-canonical varint")
+	// ErrInvalidKey 键不是合法的UTF-8
+	ErrInvalidKey = errors.New("coredata: invalid key")
+	// ErrDuplicateKey 键重复
+	ErrDuplicateKey = errors.New("coredata: duplicate key")
+	// ErrTrailingData 最后一个键值对之后还有多余字节
+	ErrTrailingData = errors.New("coredata: trailing data")
+)
+
+// Entry 一个键值对
+type Entry struct {
+	Key   string
+	Value *big.Int
+}
+
+// Entries 按编码顺序排列的键值对
+type Entries []Entry
+
+// Map 转换为键到值的映射
+func (es Entries) Map() map[string]*big.Int {
+	m := make(map[string]*big.Int, len(es))
+	for _, e := range es {
+		m[e.Key] = e.Value
+	}
+	return m
+}
+
+// Strings 转换为键到十进制字符串的映射（uint256超出JSON数字的精度）
+func (es Entries) Strings() map[string]string {
+	m := make(map[string]string, len(es))
+	for _, e := range es {
+		m[e.Key] = e.Value.String()
+	}
+	return m
+}
+
+// Serialize 按给定顺序编码键值对
+func Serialize(entries Entries) ([]byte, error) {
+	seen := make(map[string]struct{}, len(entries))
+	buf := appendVarint(nil, new(big.Int).SetInt64(int64(len(entries))))
+	for _, e := range entries {
+		if !utf8.ValidString(e.Key) {
+			return nil, fmt.Errorf("%w: %q is not valid UTF-8", ErrInvalidKey, e.Key)
+		}
+		if _, ok := seen[e.Key]; ok {
+			return nil, fmt.Errorf("%w: %q", ErrDuplicateKey, e.Key)
+		}
+		seen[e.Key] = struct{}{}
+		if e.Value == nil || e.Value.Sign() < 0 || e.Value.Cmp(MaxValue) > 0 {
+			return nil, fmt.Errorf("%w: value of %q must be between 0 and 2^256-1", ErrOverflow, e.Key)
+		}
+
+		buf = appendVarint(buf, new(big.Int).SetInt64(int64(len(e.Key))))
+		buf = append(buf, e.Key...)
+		buf = appendVarint(buf, e.Value)
+	}
+	return buf, nil
+}
+
+// SerializeMap 按键的字节序编码映射，保证同一映射的编码唯一
+// 前端按对象属性顺序编码，需要与前端的编码结果一致时应使用Serialize并给出相同的顺序
+func SerializeMap(values map[string]*big.Int) ([]byte, error) {
+	entries := make(Entries, 0, len(values))
+	for k, v := range values {
+		entries = append(entries, Entry{Key: k, Value: v})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return Serialize(entries)
+}
+
+// Deserialize 解码核心数据，返回按编码顺序排列的键值对
+func Deserialize(data []byte) (Entries, error) {
+	d := decoder{data: data}
+
+	count, err := d.length()
+	if err != nil {
+		return nil, fmt.Errorf("entry count: %w", err)
+	}
+	entries := make(Entries, 0, count)
+	seen := make(map[string]struct{}, count)
+	for i := 0; i < count; i++ {
+		keyLen, err := d.length()
+		if err != nil {
+			return nil, fmt.Errorf("key length of entry %d: %w", i, err)
+		}
+		key := string(d.data[d.offset : d.offset+keyLen])
+		d.offset += keyLen
+		if !utf8.ValidString(key) {
+			return nil, fmt.Errorf("%w: key of entry %d is not valid UTF-8", ErrInvalidKey, i)
+		}
+		if _, ok := seen[key]; ok {
+			return nil, fmt.Errorf("%w: %q", ErrDuplicateKey, key)
+		}
+		seen[key] = struct{}{}
+
+		value, err := d.varint()
+		if err != nil {
+			return nil, fmt.Errorf("value of %q: %w", key, err)
+		}
+		entries = append(entries, Entry{Key: key, Value: value})
+	}
+
+	if d.offset != len(data) {
+		return nil, fmt.Errorf("%w: %d bytes after last entry", ErrTrailingData, len(data)-d.offset)
+	}
+	return entries, nil
+}
+
+// appendVarint 将非负整数按protobuf风格的varint追加到buf
+func appendVarint(buf []byte, v *big.Int) []byte {
+	n := new(big.Int).Set(v)
+	group := new(big.Int)
+	mask := big.NewInt(0x7f)
+	for {
+		b := byte(group.And(n, mask).Uint64())
+		n.Rsh(n, 7)
+		if n.Sign() == 0 {
+			return append(buf, b)
+		}
+		buf = append(buf, b|0x80)
+	}
+}
+
+// decoder 按顺序读取核心数据
+type decoder struct {
+	data   []byte
+	offset int
+}
+
+// varint 读取一个规范编码的uint256 varint
+func (d *decoder) varint() (*big.Int, error) {
+	value := new(big.Int)
+	for i := 0; ; i++ {
+		if i >= maxVarintLen {
+			return nil, fmt.Errorf("%w: varint longer than %d bytes", ErrOverflow, maxVarintLen)
+		}
+		if d.offset+i >= len(d.data) {
+			return nil, ErrTruncated
+		}
+		b := d.data[d.offset+i]
+		value.Or(value, new(big.Int).Lsh(big.NewInt(int64(b&0x7f)), uint(7*i)))
+		if b&0x80 != 0 {
+			continue
+		}
+
+		// 多字节编码的最后一个字节为0说明带有多余的高位零
+		if b == 0 && i > 0 {
+			return nil, ErrNonCanonical
+		}
+		if value.Cmp(MaxValue) > 0 {
+			return nil, fmt.Errorf("%w: value exceeds 2^256-1", ErrOverflow)
+		}
+		d.offset += i + 1
+		return value, nil
+	}
+}
+
+// length 读取一个数量或长度，它不能超过剩余的字节数
+// （每个键值对至少占2个字节，键长度为n时键占n个字节）
+func (d *decoder) length() (int, error) {
+	v, err := d.varint()
+	if err != nil {
+		return 0, err
+	}
+	if !v.IsInt64() || v.Int64() > int64(len(d.data)-d.offset) {
+		return 0, fmt.Errorf("%w: length %s exceeds remaining %d bytes", ErrTruncated, v, len(d.data)-d.offset)
+	}
+	return int(v.Int64()), nil
+}
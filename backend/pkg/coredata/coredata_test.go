@@ -0,0 +1,137 @@
+package coredata
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+)
+
+// vectors 与前端dataSerializer.js共用的测试向量（testdata/vectors.json）
+type vectors struct {
+	Valid []struct {
+		Name    string `json:"name"`
+		Entries []struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		} `json:"entries"`
+		Hex string `json:"hex"`
+	} `json:"valid"`
+	Invalid []struct {
+		Name  string `json:"name"`
+		Hex   string `json:"hex"`
+		Error string `json:"error"`
+	} `json:"invalid"`
+}
+
+// vectorErrors 测试向量中的错误名对应的错误
+var vectorErrors = map[string]error{
+	"truncated":     ErrTruncated,
+	"non-canonical": ErrNonCanonical,
+	"overflow":      ErrOverflow,
+	"invalid-key":   ErrInvalidKey,
+	"duplicate-key": ErrDuplicateKey,
+	"trailing-data": ErrTrailingData,
+}
+
+func loadVectors(t *testing.T) *vectors {
+	t.Helper()
+	data, err := os.ReadFile("testdata/vectors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v vectors
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatal(err)
+	}
+	if len(v.Valid) == 0 || len(v.Invalid) == 0 {
+		t.Fatal("testdata/vectors.json has no vectors")
+	}
+	return &v
+}
+
+func decodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		t.Fatalf("invalid hex %q: %v", s, err)
+	}
+	return b
+}
+
+func TestValidVectors(t *testing.T) {
+	for _, vector := range loadVectors(t).Valid {
+		t.Run(vector.Name, func(t *testing.T) {
+			entries := make(Entries, 0, len(vector.Entries))
+			for _, e := range vector.Entries {
+				value, ok := new(big.Int).SetString(e.Value, 10)
+				if !ok {
+					t.Fatalf("invalid value %q", e.Value)
+				}
+				entries = append(entries, Entry{Key: e.Key, Value: value})
+			}
+			want := decodeHex(t, vector.Hex)
+
+			encoded, err := Serialize(entries)
+			if err != nil {
+				t.Fatalf("Serialize: %v", err)
+			}
+			if !bytes.Equal(encoded, want) {
+				t.Fatalf("Serialize = 0x%x, want %s", encoded, vector.Hex)
+			}
+
+			decoded, err := Deserialize(want)
+			if err != nil {
+				t.Fatalf("Deserialize: %v", err)
+			}
+			if len(decoded) != len(entries) {
+				t.Fatalf("Deserialize returned %d entries, want %d", len(decoded), len(entries))
+			}
+			for i := range entries {
+				if decoded[i].Key != entries[i].Key || decoded[i].Value.Cmp(entries[i].Value) != 0 {
+					t.Fatalf("entry %d = %q:%s, want %q:%s", i, decoded[i].Key, decoded[i].Value, entries[i].Key, entries[i].Value)
+				}
+			}
+		})
+	}
+}
+
+func TestInvalidVectors(t *testing.T) {
+	for _, vector := range loadVectors(t).Invalid {
+		t.Run(vector.Name, func(t *testing.T) {
+			want, ok := vectorErrors[vector.Error]
+			if !ok {
+				t.Fatalf("unknown error %q in vector", vector.Error)
+			}
+			entries, err := Deserialize(decodeHex(t, vector.Hex))
+			if !errors.Is(err, want) {
+				t.Fatalf("Deserialize(%s) = %v, %v; want %v", vector.Hex, entries, err, want)
+			}
+		})
+	}
+}
+
+func TestSerializeRejectsInvalidEntries(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries Entries
+		want    error
+	}{
+		{name: "negative value", entries: Entries{{Key: "a", Value: big.NewInt(-1)}}, want: ErrOverflow},
+		{name: "nil value", entries: Entries{{Key: "a"}}, want: ErrOverflow},
+		{name: "value exceeds uint256", entries: Entries{{Key: "a", Value: new(big.Int).Add(MaxValue, big.NewInt(1))}}, want: ErrOverflow},
+		{name: "invalid utf-8 key", entries: Entries{{Key: "\xff", Value: big.NewInt(1)}}, want: ErrInvalidKey},
+		{name: "duplicate key", entries: Entries{{Key: "a", Value: big.NewInt(1)}, {Key: "a", Value: big.NewInt(2)}}, want: ErrDuplicateKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Serialize(tt.entries); !errors.Is(err, tt.want) {
+				t.Fatalf("Serialize error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// Package dataid 离线实现Oracle合约中按日期生成的数据ID（did），对应合约的纯函数
+// encodeYearMonthDayToDid、decodeDidToYearMonthDay、isValidYearMonthDayDid：
+// did为左对齐的ASCII字符串YYYYMMDD，其余字节为0（前端用ethers.decodeBytes32String显示），
+// 同一年月的did共享前缀YYYYMM。
+// 后端启动时会用样本日期逐链与合约的纯函数核对，只有结果一致的链才使用离线实现。
+package dataid
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DateLayout 前端提交的数据日期格式（dayjs的YYYY-MM-DD）
+const DateLayout = "2006-01-02"
+
+// encodedLen did中日期字符串的长度（YYYYMMDD）
+const encodedLen = 8
+
+// ErrInvalidDate 年月日不是有效的日期
+var ErrInvalidDate = errors.New("dataid: invalid date")
+
+// ErrInvalidDid 数据ID不是有效的日期编码
+var ErrInvalidDid = errors.New("dataid: invalid date did")
+
+// Encode 将日期编码为数据ID
+func Encode(year uint16, month, day uint8) ([32]byte, error) {
+	if !validDate(year, month, day) {
+		return [32]byte{}, fmt.Errorf("%w: %04d-%02d-%02d", ErrInvalidDate, year, month, day)
+	}
+	var did [32]byte
+	copy(did[:], fmt.Sprintf("%04d%02d%02d", year, month, day))
+	return did, nil
+}
+
+// Decode 将数据ID解码为日期
+func Decode(did [32]byte) (year uint16, month, day uint8, err error) {
+	for _, b := range did[encodedLen:] {
+		if b != 0 {
+			return 0, 0, 0, ErrInvalidDid
+		}
+	}
+	var digits [encodedLen]int
+	for i, b := range did[:encodedLen] {
+		if b < '0' || b > '9' {
+			return 0, 0, 0, ErrInvalidDid
+		}
+		digits[i] = int(b - '0')
+	}
+
+	year = uint16(digits[0]*1000 + digits[1]*100 + digits[2]*10 + digits[3])
+	month = uint8(digits[4]*10 + digits[5])
+	day = uint8(digits[6]*10 + digits[7])
+	if !validDate(year, month, day) {
+		return 0, 0, 0, ErrInvalidDid
+	}
+	return year, month, day, nil
+}
+
+// IsValid 检查数据ID是否为有效日期的编码
+func IsValid(did [32]byte) bool {
+	_, _, _, err := Decode(did)
+	return err == nil
+}
+
+// ParseDate 解析YYYY-MM-DD格式的数据日期
+func ParseDate(date string) (year uint16, month, day uint8, err error) {
+	t, err := time.Parse(DateLayout, date)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("%w %q: %v", ErrInvalidDate, date, err)
+	}
+	if t.Year() < 1 {
+		return 0, 0, 0, fmt.Errorf("%w %q", ErrInvalidDate, date)
+	}
+	return uint16(t.Year()), uint8(t.Month()), uint8(t.Day()), nil
+}
+
+// FromDate 将YYYY-MM-DD格式的数据日期编码为数据ID
+func FromDate(date string) ([32]byte, error) {
+	year, month, day, err := ParseDate(date)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return Encode(year, month, day)
+}
+
+// validDate 检查年月日是否为公历中存在的日期（年份1-9999）
+func validDate(year uint16, month, day uint8) bool {
+	if year < 1 || year > 9999 || month < 1 || month > 12 || day < 1 {
+		return false
+	}
+	return int(day) <= daysIn(year, month)
+}
+
+// daysIn 返回指定年月的天数
+func daysIn(year uint16, month uint8) int {
+	switch month {
+	case 2:
+		if year%4 == 0 && (year%100 != 0 || year%400 == 0) {
+			return 29
+		}
+		return 28
+	case 4, 6, 9, 11:
+		return 30
+	default:
+		return 31
+	}
+}
@@ -0,0 +1,168 @@
+package dataid
+
+import (
+	"errors"
+	"testing"
+)
+
+func did(s string) [32]byte {
+	var d [32]byte
+	copy(d[:], s)
+	return d
+}
+
+func TestEncodeDecode(t *testing.T) {
+	tests := []struct {
+		name       string
+		year       uint16
+		month, day uint8
+		want       string
+	}{
+		{name: "leap day", year: 2024, month: 2, day: 29, want: "20240229"},
+		{name: "leap day divisible by 400", year: 2000, month: 2, day: 29, want: "20000229"},
+		{name: "february end", year: 2025, month: 2, day: 28, want: "20250228"},
+		{name: "30-day month end", year: 2099, month: 4, day: 30, want: "20990430"},
+		{name: "31-day month end", year: 2025, month: 12, day: 31, want: "20251231"},
+		{name: "first day", year: 2025, month: 1, day: 1, want: "20250101"},
+		{name: "minimum year", year: 1, month: 1, day: 1, want: "00010101"},
+		{name: "maximum year", year: 9999, month: 12, day: 31, want: "99991231"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Encode(tt.year, tt.month, tt.day)
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			if got != did(tt.want) {
+				t.Fatalf("Encode = %x, want %q zero padded", got, tt.want)
+			}
+
+			year, month, day, err := Decode(got)
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+			if year != tt.year || month != tt.month || day != tt.day {
+				t.Fatalf("Decode = %d-%d-%d, want %d-%d-%d", year, month, day, tt.year, tt.month, tt.day)
+			}
+			if !IsValid(got) {
+				t.Fatal("IsValid = false")
+			}
+		})
+	}
+}
+
+func TestEncodeInvalidDate(t *testing.T) {
+	tests := []struct {
+		name       string
+		year       uint16
+		month, day uint8
+	}{
+		{name: "february 29 in common year", year: 2025, month: 2, day: 29},
+		{name: "february 29 in century year", year: 1900, month: 2, day: 29},
+		{name: "february 30", year: 2024, month: 2, day: 30},
+		{name: "april 31", year: 2025, month: 4, day: 31},
+		{name: "november 31", year: 2025, month: 11, day: 31},
+		{name: "month 13", year: 2025, month: 13, day: 1},
+		{name: "month 0", year: 2025, month: 0, day: 1},
+		{name: "day 0", year: 2025, month: 1, day: 0},
+		{name: "year 0", year: 0, month: 1, day: 1},
+		{name: "year 10000", year: 10000, month: 1, day: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := Encode(tt.year, tt.month, tt.day); !errors.Is(err, ErrInvalidDate) {
+				t.Fatalf("Encode = %x, %v; want ErrInvalidDate", got, err)
+			}
+		})
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	nonZeroTail := did("20250101")
+	nonZeroTail[8] = '0'
+	lastByte := did("20250101")
+	lastByte[31] = 1
+
+	tests := []struct {
+		name string
+		did  [32]byte
+	}{
+		{name: "all zero", did: [32]byte{}},
+		{name: "dashed date", did: did("2025-1-1")},
+		{name: "letter", did: did("2025O101")},
+		{name: "space", did: did("2025 101")},
+		{name: "byte below digits", did: did("2025/101")},
+		{name: "byte above digits", did: did("2025:101")},
+		{name: "non-ascii", did: did("2025\xff101")},
+		{name: "short date", did: did("2025011")},
+		{name: "digit after date", did: nonZeroTail},
+		{name: "non-zero last byte", did: lastByte},
+		{name: "right aligned", did: func() [32]byte { var d [32]byte; copy(d[24:], "20250101"); return d }()},
+		{name: "february 29 in common year", did: did("20250229")},
+		{name: "february 29 in century year", did: did("19000229")},
+		{name: "april 31", did: did("20250431")},
+		{name: "month 13", did: did("20251301")},
+		{name: "day 0", did: did("20250400")},
+		{name: "year 0", did: did("00000101")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, _, err := Decode(tt.did); !errors.Is(err, ErrInvalidDid) {
+				t.Fatalf("Decode(%x) error = %v, want ErrInvalidDid", tt.did, err)
+			}
+			if IsValid(tt.did) {
+				t.Fatalf("IsValid(%x) = true", tt.did)
+			}
+		})
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	tests := []struct {
+		date       string
+		year       uint16
+		month, day uint8
+		wantErr    bool
+	}{
+		{date: "2024-02-29", year: 2024, month: 2, day: 29},
+		{date: "2000-02-29", year: 2000, month: 2, day: 29},
+		{date: "2025-12-31", year: 2025, month: 12, day: 31},
+		{date: "2025-02-29", wantErr: true},
+		{date: "2025-04-31", wantErr: true},
+		{date: "2025-13-01", wantErr: true},
+		{date: "0000-01-01", wantErr: true},
+		{date: "2025-1-1", wantErr: true},
+		{date: "20250101", wantErr: true},
+		{date: "2025/01/01", wantErr: true},
+		// 旧版前端提交的UTC时间戳无法确定本地日期，不接受
+		{date: "2025-01-01T00:00:00.000Z", wantErr: true},
+		{date: "1735689600000", wantErr: true},
+		{date: " 2025-01-01", wantErr: true},
+		{date: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.date, func(t *testing.T) {
+			year, month, day, err := ParseDate(tt.date)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidDate) {
+					t.Fatalf("ParseDate(%q) = %d-%d-%d, %v; want ErrInvalidDate", tt.date, year, month, day, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseDate(%q): %v", tt.date, err)
+			}
+			if year != tt.year || month != tt.month || day != tt.day {
+				t.Fatalf("ParseDate(%q) = %d-%d-%d", tt.date, year, month, day)
+			}
+
+			got, err := FromDate(tt.date)
+			if err != nil {
+				t.Fatalf("FromDate(%q): %v", tt.date, err)
+			}
+			if want, _ := Encode(tt.year, tt.month, tt.day); got != want {
+				t.Fatalf("FromDate(%q) = %x, want %x", tt.date, got, want)
+			}
+		})
+	}
+}
@@ -972,15 +972,31 @@ const FileUploadForm = () => {
       message.info('正在生成签名数据...');
       
       // 获取所有需要签名的数据
-      const dataDate = form.getFieldValue('dataDate');
+      // 数据日期统一格式化为YYYY-MM-DD，签名数据、EIP-712消息和表单字段使用同一字符串，服务端会校验三者一致
+      const dataDateValue = form.getFieldValue('dataDate');
+      if (!dataDateValue) {
+        throw new Error('请选择数据日期');
+      }
+      const dataDate = dayjs.isDayjs(dataDateValue) ? dataDateValue.format('YYYY-MM-DD') : String(dataDateValue);
+      
+      // 签名绑定当前链，链ID未获取到时不能签名
+      if (!currentChainId) {
+        throw new Error('未获取到当前链ID，请检查钱包网络');
+      }
+      
+      // 获取服务端签发的一次性nonce（防重放）
+      const nonceResponse = await axios.get('/api/nonce', { params: { address } });
+      const nonce = nonceResponse.data.data.nonce;
       
-      // 构建待签名消息
+      // 构建待签名消息（EIP-712 OracleUpload）
       const signatureData = {
         projectId: projectId,
         dataDate: dataDate,
         coreDataHash: '', // 需要计算核心数据的哈希
         fileHashes: hashResults.map(h => h.hashValue),
+        chainId: Number(currentChainId),
         timestamp: Date.now(),
+        nonce: nonce,
       };
       
       // 计算核心数据的哈希
@@ -998,17 +1014,42 @@ const FileUploadForm = () => {
       // 将签名数据转换为字符串
       const signatureMessage = JSON.stringify(signatureData);
       
-      // 3. 使用钱包签名
+      // 3. 使用钱包签名（EIP-712结构化数据，域绑定当前链和合约地址）
       message.info('请确认钱包签名...');
       const signer = await provider.getSigner();
-      const signature = await signer.signMessage(signatureMessage);
+      const signature = await signer.signTypedData(
+        {
+          name: 'Oracle',
+          version: '1',
+          chainId: signatureData.chainId,
+          verifyingContract: contractAddress,
+        },
+        {
+          OracleUpload: [
+            { name: 'projectId', type: 'string' },
+            { name: 'dataDate', type: 'string' },
+            { name: 'coreDataHash', type: 'bytes32' },
+            { name: 'fileHashes', type: 'bytes32[]' },
+            { name: 'chainId', type: 'uint256' },
+            { name: 'timestamp', type: 'uint256' },
+            { name: 'nonce', type: 'uint256' },
+          ],
+        },
+        {
+          projectId: signatureData.projectId,
+          dataDate: signatureData.dataDate,
+          coreDataHash: signatureData.coreDataHash,
+          fileHashes: signatureData.fileHashes.map(h => (h.startsWith('0x') ? h : `0x${h}`)),
+          chainId: signatureData.chainId,
+          timestamp: signatureData.timestamp,
+          nonce: signatureData.nonce,
+        }
+      );
       
       // 4. 提交到后台（包含签名数据）
       message.info('开始提交到后台...');
+      // 服务端按顺序读取请求体，表单字段须在文件之前
       const formData = new FormData();
-      selectedFiles.forEach(file => {
-        formData.append('files', file.raw || file.originFileObj);
-      });
       
       // 获取当前选中的项目ID和项目描述
       const projectDescription = selectedProject ? selectedProject.description : '';
@@ -1020,15 +1061,18 @@ const FileUploadForm = () => {
       formData.append('hashResults', JSON.stringify(hashResults));
       
       // 添加签名数据
+      formData.append('signatureType', 'eip712');
       formData.append('signatureData', signatureMessage);
       formData.append('signature', signature);
+      // 声明签名者地址，合约钱包（ERC-1271）签名需由服务端调用钱包合约验证
+      formData.append('signerAddress', address);
       
-      // 添加当前链ID
-      if (currentChainId) {
-        formData.append('chainId', currentChainId);
-      } else {
-        console.warn('当前链ID未获取到，未添加到上传请求中');
-      }
+      // 添加当前链ID（必须与签名数据中的chainId一致，服务端会校验）
+      formData.append('chainId', String(signatureData.chainId));
+      
+      selectedFiles.forEach(file => {
+        formData.append('files', file.raw || file.originFileObj);
+      });
       
       await axios.post('/api/upload', formData, {
         headers: {
@@ -156,8 +156,8 @@ class DataSerializer {
       shift += 7n;
       bytesRead++;
       
-      // 防止过大的数字导致性能问题（uint256最多需要35字节）
-      if (bytesRead > 35) {
+      // 防止过大的数字导致性能问题（uint256最多需要37字节：256/7向上取整）
+      if (bytesRead > 37) {
         throw new Error('varint编码过长，超过uint256范围');
       }
     } while ((byte & 0x80) !== 0);
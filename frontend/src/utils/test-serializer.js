@@ -3,7 +3,7 @@ const fs = require('fs');
 const path = require('path');
 
 // 读取dataSerializer.js文件内容
-const serializerPath = path.join(__dirname, 'src', 'utils', 'dataSerializer.js');
+const serializerPath = path.join(__dirname, 'dataSerializer.js');
 const serializerContent = fs.readFileSync(serializerPath, 'utf8');
 
 // 移除ES6模块导出和示例代码，使其能在Node.js中直接运行
@@ -12,8 +12,8 @@ const modifiedContent = serializerContent
   .replace('export { toHexString, fromHexString };', '')
   .replace(/^\/\/ 使用示例[\s\S]*$/, '');
 
-// 执行修改后的代码
- eval(modifiedContent);
+// 执行修改后的代码（class声明只在eval内部可见，需要作为结果取出）
+const DataSerializer = eval(modifiedContent + '\nDataSerializer');
 
 // 测试数据
 const testData = {
@@ -44,3 +44,28 @@ console.log('key1一致:', testData.key1.toString() === decoded.key1.toString())
 console.log('key2一致:', testData.key2.toString() === decoded.key2.toString());
 console.log('key3一致:', testData.key3.toString() === decoded.key3.toString());
 console.log('key4一致:', testData.key4.toString() === decoded.key4.toString());
+
+// 与后端Go实现（backend/pkg/coredata）共用的测试向量
+const vectorsPath = path.join(__dirname, '..', '..', '..', 'backend', 'pkg', 'coredata', 'testdata', 'vectors.json');
+const vectors = JSON.parse(fs.readFileSync(vectorsPath, 'utf8'));
+
+let failed = 0;
+for (const vector of vectors.valid) {
+  const data = {};
+  vector.entries.forEach(({ key, value }) => {
+    data[key] = BigInt(value);
+  });
+  // 纯数字的键在JS对象中会被重新排序，这类向量只检查解码
+  const orderPreserved = Object.keys(data).join('\u0000') === vector.entries.map(e => e.key).join('\u0000');
+  const encodedHex = '0x' + toHexString(DataSerializer.serialize(data));
+  const decodedVector = DataSerializer.deserialize(vector.hex);
+  const decodedOk = vector.entries.every(({ key, value }) => decodedVector[key]?.toString() === value);
+  if ((orderPreserved && encodedHex !== vector.hex) || !decodedOk) {
+    failed++;
+    console.log('测试向量不一致:', vector.name, encodedHex, vector.hex);
+  }
+}
+console.log(`测试向量: ${vectors.valid.length - failed}/${vectors.valid.length} 一致`);
+if (failed > 0) {
+  process.exit(1);
+}